@@ -19,6 +19,12 @@ type Config struct {
 	LogLevel           slog.Level     `toml:"log_level"`
 	NoPanic            bool           `toml:"no_panic"`
 	DataUpdateInterval time.Duration  `toml:"data_update_interval"`
+	MaxTimerDuration   time.Duration  `toml:"max_timer_duration"`
+	// ChartRenderer is either "local" or "quickchart"
+	ChartRenderer string `toml:"chart_renderer"`
+	// ChartFont is the path of a font used by the local chart renderer for
+	// the characters its default font has no glyphs for, such as Japanese
+	ChartFont string `toml:"chart_font"`
 }
 
 type DatabaseConfig struct {
@@ -62,6 +68,14 @@ func (c *Config) LoadDefaults() {
 		c.DataUpdateInterval = 7 * 24 * time.Hour
 	}
 
+	if c.MaxTimerDuration.Abs() == 0 {
+		c.MaxTimerDuration = 12 * time.Hour
+	}
+
+	if c.ChartRenderer == "" {
+		c.ChartRenderer = "local"
+	}
+
 	if c.Database.SSLMode == "" {
 		c.Database.SSLMode = "disable"
 	}
@@ -184,6 +198,30 @@ func (c *Config) LoadEnv() error {
 		c.DataUpdateInterval = duration
 	}
 
+	maxTimerDuration, ok := os.LookupEnv("BOTSU_MAX_TIMER_DURATION")
+
+	if ok {
+		duration, err := time.ParseDuration(maxTimerDuration)
+
+		if err != nil {
+			return err
+		}
+
+		c.MaxTimerDuration = duration
+	}
+
+	chartRenderer, ok := os.LookupEnv("BOTSU_CHART_RENDERER")
+
+	if ok {
+		c.ChartRenderer = chartRenderer
+	}
+
+	chartFont, ok := os.LookupEnv("BOTSU_CHART_FONT")
+
+	if ok {
+		c.ChartFont = chartFont
+	}
+
 	return nil
 }
 
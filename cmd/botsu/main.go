@@ -13,14 +13,17 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/UTD-JLA/botsu/internal/achievements"
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
 	"github.com/UTD-JLA/botsu/internal/bot/commands"
 	"github.com/UTD-JLA/botsu/internal/goals"
 	"github.com/UTD-JLA/botsu/internal/guilds"
 	"github.com/UTD-JLA/botsu/internal/mediadata"
+	"github.com/UTD-JLA/botsu/internal/timers"
 	"github.com/UTD-JLA/botsu/internal/users"
 	"github.com/UTD-JLA/botsu/migrations"
+	"github.com/UTD-JLA/botsu/pkg/chart"
 	"github.com/bwmarrin/discordgo"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
@@ -70,6 +73,26 @@ func main() {
 	logLevel.Set(config.LogLevel)
 	logger.Info("Log level set", slog.String("level", config.LogLevel.String()))
 
+	chartRenderer, err := commands.NewChartRenderer(config.ChartRenderer)
+
+	if err != nil {
+		logger.Error("Invalid chart renderer", slog.String("err", err.Error()))
+		os.Exit(1)
+	}
+
+	if config.ChartFont != "" {
+		fontData, err := os.ReadFile(config.ChartFont)
+
+		if err == nil {
+			err = chart.AddFallbackFont(fontData)
+		}
+
+		if err != nil {
+			logger.Error("Unable to load chart font", slog.String("err", err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	discordgo.Logger = func(msgL, _caller int, format string, a ...interface{}) {
 		msg := fmt.Sprintf("[DGO] "+format, a...)
 
@@ -186,25 +209,44 @@ func main() {
 	defer pool.Close()
 
 	activityRepo := activities.NewActivityRepository(pool)
+	pointRuleRepo := activities.NewPointRuleRepository(pool)
+	achievementService := achievements.NewAchievementService(achievements.NewAchievementRepository(pool))
 	userRepo := users.NewUserRepository(pool)
 	guildRepo := guilds.NewGuildRepository(pool)
 	timeService := users.NewUserTimeService(userRepo, guildRepo)
+	streakService := users.NewStreakService(userRepo, activityRepo, timeService)
 	goalRepo := goals.NewGoalRepository(pool)
-	goalService := goals.NewGoalService(goalRepo, timeService)
+	goalService := goals.NewGoalService(goalRepo, activityRepo, timeService)
+	guildGoalRepo := goals.NewGuildGoalRepository(pool)
+	guildGoalService := goals.NewGuildGoalService(guildGoalRepo, activityRepo, timeService)
+	timerRepo := timers.NewTimerRepository(pool)
+	timerService := timers.NewTimerService(timerRepo, activityRepo, goalService, guildGoalService, streakService, logger.WithGroup("timers"))
+	timerService.MaxDuration = config.MaxTimerDuration
+	milestoneRoleSyncer := commands.NewMilestoneRoleSyncer(activityRepo, guildRepo, logger.WithGroup("milestones"))
 
 	bot := bot.NewBot(logger.WithGroup("bot"), guildRepo)
 	bot.SetNoPanic(config.NoPanic)
 
-	bot.AddCommand(commands.LogCommandData, commands.NewLogCommand(activityRepo, userRepo, guildRepo, mediaSearcher, goalService, timeService))
+	bot.AddCommand(commands.LogCommandData, commands.NewLogCommand(activityRepo, userRepo, guildRepo, mediaSearcher, goalService, guildGoalService, timeService, pointRuleRepo, milestoneRoleSyncer, streakService, achievementService))
 	bot.AddCommand(commands.ConfigCommandData, commands.NewConfigCommand(userRepo, activityRepo))
-	bot.AddCommand(commands.HistoryCommandData, commands.NewHistoryCommand(activityRepo))
+	bot.AddCommand(commands.HistoryCommandData, commands.NewHistoryCommand(activityRepo, userRepo, timeService))
 	bot.AddCommand(commands.LeaderboardCommandData, commands.NewLeaderboardCommand(activityRepo, userRepo, guildRepo))
-	bot.AddCommand(commands.UndoCommandData, commands.NewUndoCommand(activityRepo))
-	bot.AddCommand(commands.ChartCommandData, commands.NewChartCommand(activityRepo, userRepo, guildRepo))
+	bot.AddCommand(commands.UndoCommandData, commands.NewUndoCommand(activityRepo, goalService, guildGoalService, streakService, milestoneRoleSyncer))
+	bot.AddCommand(commands.ChartCommandData, commands.NewChartCommand(activityRepo, userRepo, guildRepo, chartRenderer))
+	bot.AddCommand(commands.StatsCommandData, commands.NewStatsCommand(activityRepo, userRepo, timeService, streakService))
+	bot.AddCommand(commands.StreakCommandData, commands.NewStreakCommand(userRepo, streakService))
+	bot.AddCommand(commands.AchievementsCommandData, commands.NewAchievementsCommand(userRepo, streakService, achievementService))
+	bot.AddCommand(commands.MediaCommandData, commands.NewMediaCommand(activityRepo, mediaSearcher))
+	bot.AddCommand(commands.WrappedCommandData, commands.NewWrappedCommand(activityRepo, goalRepo, timeService, chartRenderer))
 	bot.AddCommand(commands.GuildConfigCommandData, commands.NewGuildConfigCommand(guildRepo))
 	bot.AddCommand(commands.ExportCommandData, commands.NewExportCommand(activityRepo))
-	bot.AddCommand(commands.ImportCommandData, commands.NewImportCommand(activityRepo))
-	bot.AddCommand(commands.GoalCommandData, commands.NewGoalCommand(goalService))
+	bot.AddCommand(commands.ImportCommandData, commands.NewImportCommand(activityRepo, goalService, guildGoalService, milestoneRoleSyncer, streakService))
+	bot.AddCommand(commands.GoalCommandData, commands.NewGoalCommand(goalService, mediaSearcher))
+	bot.AddCommand(commands.TimerCommandData, commands.NewTimerCommand(timerService, milestoneRoleSyncer, streakService, achievementService))
+	bot.AddCommand(commands.EditCommandData, commands.NewEditCommand(activityRepo, goalService, guildGoalService, timeService, streakService, milestoneRoleSyncer))
+	bot.AddCommand(commands.GuildGoalCommandData, commands.NewGuildGoalCommand(guildGoalService, guildRepo, mediaSearcher))
+	bot.AddCommand(commands.GuildPointsCommandData, commands.NewGuildPointsCommand(pointRuleRepo, guildRepo))
+	bot.AddCommand(commands.GuildMilestonesCommandData, commands.NewGuildMilestonesCommand(guildRepo, milestoneRoleSyncer))
 	logger.Info("Starting bot")
 
 	intents := discordgo.IntentsNone
@@ -222,6 +264,51 @@ func main() {
 
 	defer bot.Close()
 
+	timerTicker := time.NewTicker(5 * time.Minute)
+	defer timerTicker.Stop()
+
+	go func() {
+		for range timerTicker.C {
+			closed, err := timerService.CloseExpired(context.Background())
+			if err != nil {
+				logger.Error("Unable to close expired timers", slog.String("err", err.Error()))
+			} else if closed > 0 {
+				logger.Info("Closed expired timers", slog.Int("count", closed))
+			}
+		}
+	}()
+
+	goalReminderSender := commands.NewGoalReminderSender(goalService, logger.WithGroup("reminders"))
+	guildGoalAnnouncer := commands.NewGuildGoalAnnouncer(guildGoalService, logger.WithGroup("announcements"))
+	leaderboardPoster := commands.NewLeaderboardPoster(activityRepo, guildRepo, logger.WithGroup("leaderboards"))
+	reminderTicker := time.NewTicker(time.Minute)
+	defer reminderTicker.Stop()
+
+	go func() {
+		for range reminderTicker.C {
+			sent, err := goalReminderSender.Send(context.Background(), bot.Session())
+			if err != nil {
+				logger.Error("Unable to send goal reminders", slog.String("err", err.Error()))
+			} else if sent > 0 {
+				logger.Info("Sent goal reminders", slog.Int("count", sent))
+			}
+
+			announced, err := guildGoalAnnouncer.Send(context.Background(), bot.Session())
+			if err != nil {
+				logger.Error("Unable to announce guild goals", slog.String("err", err.Error()))
+			} else if announced > 0 {
+				logger.Info("Announced guild goals", slog.Int("count", announced))
+			}
+
+			posted, err := leaderboardPoster.Send(context.Background(), bot.Session())
+			if err != nil {
+				logger.Error("Unable to post leaderboards", slog.String("err", err.Error()))
+			} else if posted > 0 {
+				logger.Info("Posted leaderboards", slog.Int("count", posted))
+			}
+		}
+	}()
+
 	// Wait here until CTRL-C or other term signal is received.
 	logger.Info("Setup completed, press CTRL-C to exit")
 
@@ -24,6 +24,7 @@ func init() {
 		fmt.Fprintln(flag.CommandLine.Output(), "  BOTSU_VNDB_DUMP_PATH: Path to vndb dump")
 		fmt.Fprintln(flag.CommandLine.Output(), "  BOTSU_USE_MEMBERS_INTENT: Whether to use the members intent")
 		fmt.Fprintln(flag.CommandLine.Output(), "  BOTSU_NO_PANIC: Whether to recover from panics caused by command handlers")
+		fmt.Fprintln(flag.CommandLine.Output(), "  BOTSU_CHART_RENDERER: Chart renderer to use, either local or quickchart")
 
 		fmt.Fprintln(flag.CommandLine.Output(), "\nConfig file:")
 		printTOMLStructure(
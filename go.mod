@@ -17,6 +17,7 @@ require (
 	github.com/pelletier/go-toml/v2 v2.1.0
 	github.com/stretchr/testify v1.8.4
 	github.com/wader/goutubedl v0.0.0-20230817095831-89e825670ccd
+	golang.org/x/image v0.15.0
 )
 
 require (
@@ -0,0 +1,125 @@
+package achievements
+
+import (
+	"time"
+)
+
+// Progress is what a user has logged so far, which achievements are unlocked by
+type Progress struct {
+	Activities int
+	Total      time.Duration
+	// VisualNovels is the number of activities logged for visual novels
+	VisualNovels  int
+	AnimeEpisodes int
+	// Pages is the number of pages of books and manga read
+	Pages int
+	// MostCharactersInDay is the most characters read on one day, in the user's timezone
+	MostCharactersInDay int
+	BestStreak          int
+}
+
+type Achievement struct {
+	// ID is stored for unlocked achievements and must not change
+	ID          string
+	Name        string
+	Description string
+	Unlocked    func(p *Progress) bool
+}
+
+// Registry is every achievement, in the order they are listed
+var Registry = []*Achievement{
+	{
+		ID:          "first_activity",
+		Name:        "First Steps",
+		Description: "Log your first activity.",
+		Unlocked:    func(p *Progress) bool { return p.Activities >= 1 },
+	},
+	{
+		ID:          "first_visual_novel",
+		Name:        "Visual Novel Reader",
+		Description: "Log your first visual novel.",
+		Unlocked:    func(p *Progress) bool { return p.VisualNovels >= 1 },
+	},
+	{
+		ID:          "characters_day_10000",
+		Name:        "Speed Reader",
+		Description: "Read 10,000 characters in one day.",
+		Unlocked:    func(p *Progress) bool { return p.MostCharactersInDay >= 10_000 },
+	},
+	{
+		ID:          "characters_day_50000",
+		Name:        "Marathon Reader",
+		Description: "Read 50,000 characters in one day.",
+		Unlocked:    func(p *Progress) bool { return p.MostCharactersInDay >= 50_000 },
+	},
+	{
+		ID:          "pages_1000",
+		Name:        "Page Turner",
+		Description: "Read 1,000 pages of books or manga.",
+		Unlocked:    func(p *Progress) bool { return p.Pages >= 1_000 },
+	},
+	{
+		ID:          "anime_episodes_100",
+		Name:        "Binge Watcher",
+		Description: "Watch 100 anime episodes.",
+		Unlocked:    func(p *Progress) bool { return p.AnimeEpisodes >= 100 },
+	},
+	{
+		ID:          "anime_episodes_1000",
+		Name:        "Anime Veteran",
+		Description: "Watch 1,000 anime episodes.",
+		Unlocked:    func(p *Progress) bool { return p.AnimeEpisodes >= 1_000 },
+	},
+	{
+		ID:          "streak_7",
+		Name:        "On a Roll",
+		Description: "Reach a streak of 7 days.",
+		Unlocked:    func(p *Progress) bool { return p.BestStreak >= 7 },
+	},
+	{
+		ID:          "streak_30",
+		Name:        "Creature of Habit",
+		Description: "Reach a streak of 30 days.",
+		Unlocked:    func(p *Progress) bool { return p.BestStreak >= 30 },
+	},
+	{
+		ID:          "streak_100",
+		Name:        "Unstoppable",
+		Description: "Reach a streak of 100 days.",
+		Unlocked:    func(p *Progress) bool { return p.BestStreak >= 100 },
+	},
+	{
+		ID:          "hours_100",
+		Name:        "Centurion",
+		Description: "Log 100 hours.",
+		Unlocked:    func(p *Progress) bool { return p.Total >= 100*time.Hour },
+	},
+	{
+		ID:          "hours_1000",
+		Name:        "Immersion Master",
+		Description: "Log 1,000 hours.",
+		Unlocked:    func(p *Progress) bool { return p.Total >= 1_000*time.Hour },
+	},
+}
+
+// Find returns the achievement with the given ID, or nil if there is none
+func Find(id string) *Achievement {
+	for _, a := range Registry {
+		if a.ID == id {
+			return a
+		}
+	}
+
+	return nil
+}
+
+// Unlocked returns the achievements unlocked by the progress, in registry order
+func Unlocked(p *Progress) (unlocked []*Achievement) {
+	for _, a := range Registry {
+		if a.Unlocked(p) {
+			unlocked = append(unlocked, a)
+		}
+	}
+
+	return
+}
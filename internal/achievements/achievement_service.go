@@ -0,0 +1,47 @@
+package achievements
+
+import (
+	"context"
+	"slices"
+)
+
+type AchievementService struct {
+	*AchievementRepository
+}
+
+func NewAchievementService(repo *AchievementRepository) *AchievementService {
+	return &AchievementService{repo}
+}
+
+// Check unlocks the achievements the user has earned with what they have logged so far,
+// returning those that were newly unlocked in registry order. guildID is used for the
+// timezone of users without one, and the user's streak should be updated beforehand
+func (s *AchievementService) Check(ctx context.Context, userID, guildID string) (unlocked []*Achievement, err error) {
+	progress, err := s.GetProgress(ctx, userID, guildID)
+	if err != nil {
+		return
+	}
+
+	earned := Unlocked(progress)
+	if len(earned) == 0 {
+		return
+	}
+
+	ids := make([]string, 0, len(earned))
+	for _, a := range earned {
+		ids = append(ids, a.ID)
+	}
+
+	newIDs, err := s.Unlock(ctx, userID, ids)
+	if err != nil {
+		return
+	}
+
+	for _, a := range earned {
+		if slices.Contains(newIDs, a.ID) {
+			unlocked = append(unlocked, a)
+		}
+	}
+
+	return
+}
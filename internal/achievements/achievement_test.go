@@ -0,0 +1,47 @@
+package achievements_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/achievements"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryIDsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+
+	for _, a := range achievements.Registry {
+		assert.False(t, seen[a.ID], "duplicate achievement ID %s", a.ID)
+		seen[a.ID] = true
+		assert.Same(t, a, achievements.Find(a.ID))
+	}
+
+	assert.Nil(t, achievements.Find("unknown"))
+}
+
+func TestUnlocked(t *testing.T) {
+	assert.Empty(t, achievements.Unlocked(&achievements.Progress{}))
+
+	unlocked := achievements.Unlocked(&achievements.Progress{
+		Activities:          12,
+		Total:               120 * time.Hour,
+		VisualNovels:        3,
+		MostCharactersInDay: 12_000,
+		BestStreak:          30,
+	})
+
+	ids := make([]string, 0, len(unlocked))
+	for _, a := range unlocked {
+		ids = append(ids, a.ID)
+	}
+
+	assert.Equal(t, []string{
+		"first_activity",
+		"first_visual_novel",
+		"characters_day_10000",
+		"streak_7",
+		"streak_30",
+		"hours_100",
+	}, ids)
+}
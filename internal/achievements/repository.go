@@ -0,0 +1,137 @@
+package achievements
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Unlock is an achievement a user has unlocked
+type Unlock struct {
+	UserID        string
+	AchievementID string
+	UnlockedAt    time.Time
+}
+
+type AchievementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementRepository(pool *pgxpool.Pool) *AchievementRepository {
+	return &AchievementRepository{pool: pool}
+}
+
+// FindByUserID returns the achievements the user has unlocked, earliest first
+func (r *AchievementRepository) FindByUserID(ctx context.Context, userID string) (unlocks []*Unlock, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT user_id, achievement_id, unlocked_at
+		FROM achievements
+		WHERE user_id = $1
+		ORDER BY unlocked_at, achievement_id`,
+		userID,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		unlock := &Unlock{}
+		if err = rows.Scan(&unlock.UserID, &unlock.AchievementID, &unlock.UnlockedAt); err != nil {
+			return
+		}
+
+		unlocks = append(unlocks, unlock)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// Unlock records the achievements as unlocked by the user, returning
+// the IDs of those that were not already unlocked
+func (r *AchievementRepository) Unlock(ctx context.Context, userID string, achievementIDs []string) (unlocked []string, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`INSERT INTO achievements (user_id, achievement_id)
+		SELECT $1, UNNEST($2::TEXT[])
+		ON CONFLICT DO NOTHING
+		RETURNING achievement_id`,
+		userID,
+		achievementIDs,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var id string
+		if err = rows.Scan(&id); err != nil {
+			return
+		}
+
+		unlocked = append(unlocked, id)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// metaNumber is the numeric meta value of an activity, or NULL if it is missing or not a number
+func metaNumber(key string) string {
+	return `CASE WHEN jsonb_typeof(a.meta->'` + key + `') = 'number' THEN (a.meta->'` + key + `')::numeric END`
+}
+
+// GetProgress returns what the user has logged so far,
+// guildID being used for the timezone of users without one
+func (r *AchievementRepository) GetProgress(ctx context.Context, userID, guildID string) (*Progress, error) {
+	query := `
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(a.duration), 0),
+			COUNT(*) FILTER (WHERE a.media_type = 'visual_novel'),
+			COALESCE(SUM(` + metaNumber("episodes") + `) FILTER (WHERE a.media_type = 'anime'), 0)::bigint,
+			COALESCE(SUM(` + metaNumber("pages") + `) FILTER (WHERE a.media_type IN ('book', 'manga')), 0)::bigint,
+			COALESCE((
+				SELECT MAX(days.characters)
+				FROM (
+					SELECT SUM(` + metaNumber("characters") + `) AS characters
+					FROM activities a
+					LEFT JOIN users u ON u.id = a.user_id
+					LEFT JOIN guilds g ON g.id = $2
+					WHERE a.user_id = $1
+					AND a.deleted_at IS NULL
+					GROUP BY (a.date AT TIME ZONE COALESCE(u.timezone, g.timezone, 'UTC'))::date
+				) days
+			), 0)::bigint,
+			COALESCE((SELECT best_streak FROM users WHERE id = $1), 0)
+		FROM activities a
+		WHERE a.user_id = $1
+		AND a.deleted_at IS NULL
+	`
+
+	p := &Progress{}
+
+	err := r.pool.QueryRow(ctx, query, userID, guildID).Scan(
+		&p.Activities,
+		&p.Total,
+		&p.VisualNovels,
+		&p.AnimeEpisodes,
+		&p.Pages,
+		&p.MostCharactersInDay,
+		&p.BestStreak,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
@@ -1,6 +1,7 @@
 package activities
 
 import (
+	"encoding/json"
 	"time"
 )
 
@@ -47,3 +48,96 @@ func (a *Activity) SetMeta(key string, value any) {
 
 	kv[key] = value
 }
+
+// GetMeta returns the value stored under key in the activity's meta,
+// which may either be a map (as read from the database) or a struct such as VideoInfo
+func (a *Activity) GetMeta(key string) (value any, ok bool) {
+	switch meta := a.Meta.(type) {
+	case nil:
+		return nil, false
+	case map[string]interface{}:
+		value, ok = meta[key]
+		return
+	default:
+		// use the json representation so keys match those stored in the database
+		b, err := json.Marshal(meta)
+
+		if err != nil {
+			return nil, false
+		}
+
+		kv := make(map[string]interface{})
+
+		if err = json.Unmarshal(b, &kv); err != nil {
+			return nil, false
+		}
+
+		value, ok = kv[key]
+		return
+	}
+}
+
+// GetMetaFloat returns the numeric value stored under key in the activity's meta
+func (a *Activity) GetMetaFloat(key string) (float64, bool) {
+	value, ok := a.GetMeta(key)
+
+	if !ok {
+		return 0, false
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// GetMetaString returns the string value stored under key in the activity's meta
+func (a *Activity) GetMetaString(key string) (string, bool) {
+	value, ok := a.GetMeta(key)
+
+	if !ok {
+		return "", false
+	}
+
+	s, ok := value.(string)
+	return s, ok
+}
+
+// GetMetaStrings returns the list of strings stored under key in the activity's meta
+func (a *Activity) GetMetaStrings(key string) ([]string, bool) {
+	value, ok := a.GetMeta(key)
+
+	if !ok {
+		return nil, false
+	}
+
+	switch v := value.(type) {
+	case []string:
+		return v, true
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, e := range v {
+			if s, ok := e.(string); ok {
+				strs = append(strs, s)
+			}
+		}
+		return strs, true
+	default:
+		return nil, false
+	}
+}
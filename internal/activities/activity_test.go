@@ -0,0 +1,42 @@
+package activities_test
+
+import (
+	"testing"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetMeta(t *testing.T) {
+	a := activities.NewActivity()
+	a.SetMeta("characters", uint(1200))
+	a.SetMeta("title", "test")
+
+	characters, ok := a.GetMetaFloat("characters")
+	assert.True(t, ok)
+	assert.Equal(t, 1200.0, characters)
+
+	title, ok := a.GetMetaString("title")
+	assert.True(t, ok)
+	assert.Equal(t, "test", title)
+
+	_, ok = a.GetMeta("pages")
+	assert.False(t, ok)
+
+	a.SetMeta("tags", []string{"comedy"})
+	tags, ok := a.GetMetaStrings("tags")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"comedy"}, tags)
+
+	// meta as read from the database
+	a.Meta = map[string]interface{}{"tags": []interface{}{"slice of life", "school"}}
+	tags, ok = a.GetMetaStrings("tags")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"slice of life", "school"}, tags)
+
+	a.Meta = &activities.VideoInfo{ChannelHandle: "@HakuiKoyori"}
+
+	handle, ok := a.GetMetaString("channel_handle")
+	assert.True(t, ok)
+	assert.Equal(t, "@HakuiKoyori", handle)
+}
@@ -0,0 +1,4 @@
+package activities
+
+// TotalByMediaTypeQuery is exported for tests
+const TotalByMediaTypeQuery = totalByMediaTypeQuery
@@ -0,0 +1,83 @@
+package activities
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PointRuleRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPointRuleRepository(pool *pgxpool.Pool) *PointRuleRepository {
+	return &PointRuleRepository{pool: pool}
+}
+
+// FindByGuildID returns the point rules of the guild, ordered by media type and unit
+func (r *PointRuleRepository) FindByGuildID(ctx context.Context, guildID string) (rules []*PointRule, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT guild_id, media_type, unit, amount, points
+		FROM guild_point_rules
+		WHERE guild_id = $1
+		ORDER BY media_type, unit`,
+		guildID,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		rule := &PointRule{}
+		if err = rows.Scan(&rule.GuildID, &rule.MediaType, &rule.Unit, &rule.Amount, &rule.Points); err != nil {
+			return
+		}
+
+		rules = append(rules, rule)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// Set creates the rule, replacing the guild's rule for the same media type and unit
+func (r *PointRuleRepository) Set(ctx context.Context, rule *PointRule) error {
+	_, err := r.pool.Exec(
+		ctx,
+		`INSERT INTO guild_point_rules (guild_id, media_type, unit, amount, points)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (guild_id, media_type, unit) DO UPDATE SET amount = $4, points = $5`,
+		rule.GuildID,
+		rule.MediaType,
+		rule.Unit,
+		rule.Amount,
+		rule.Points,
+	)
+
+	return err
+}
+
+// Delete removes the guild's rule for the media type and unit,
+// returning false if there was none
+func (r *PointRuleRepository) Delete(ctx context.Context, guildID, mediaType, unit string) (bool, error) {
+	tag, err := r.pool.Exec(
+		ctx,
+		`DELETE FROM guild_point_rules
+		WHERE guild_id = $1
+		AND media_type = $2
+		AND unit = $3`,
+		guildID,
+		mediaType,
+		unit,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
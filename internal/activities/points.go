@@ -0,0 +1,73 @@
+package activities
+
+// PointUnitMinutes awards points for the duration of activities,
+// the other units being the meta metrics
+const PointUnitMinutes = "minutes"
+
+// PointUnits are the units point rules can award points for
+var PointUnits = []string{PointUnitMinutes, MetricCharacters, MetricPages, MetricEpisodes}
+
+// PointRule awards Points for every Amount of Unit in activities of MediaType,
+// or if MediaType is empty, of the media types without rules of their own
+type PointRule struct {
+	GuildID   string
+	MediaType string
+	Unit      string
+	Amount    float64
+	Points    float64
+}
+
+// Points returns the points the activity earns under a guild's rules
+func Points(rules []*PointRule, a *Activity) float64 {
+	mediaType := ""
+	for _, rule := range rules {
+		if a.MediaType != nil && rule.MediaType == *a.MediaType {
+			mediaType = *a.MediaType
+			break
+		}
+	}
+
+	points := 0.0
+
+	for _, rule := range rules {
+		if rule.MediaType != mediaType {
+			continue
+		}
+
+		var value float64
+		if rule.Unit == PointUnitMinutes {
+			value = a.Duration.Minutes()
+		} else {
+			value, _ = a.GetMetaFloat(rule.Unit)
+		}
+
+		points += value / rule.Amount * rule.Points
+	}
+
+	return points
+}
+
+// pointsJoin joins activities a to the points p.points each earns under the rules
+// of guild $1, the same as Points, which is NULL for activities without any
+const pointsJoin = `
+	LEFT JOIN LATERAL (
+		SELECT SUM(
+			CASE
+				WHEN r.unit = 'minutes' THEN a.duration / 60000000000.0
+				WHEN jsonb_typeof(a.meta->r.unit) = 'number' THEN (a.meta->r.unit)::numeric
+				ELSE 0
+			END::double precision / r.amount * r.points
+		) AS points
+		FROM guild_point_rules r
+		WHERE r.guild_id = $1
+		AND r.media_type = CASE
+			WHEN EXISTS (
+				SELECT 1
+				FROM guild_point_rules t
+				WHERE t.guild_id = $1
+				AND t.media_type = a.media_type::text
+			) THEN a.media_type::text
+			ELSE ''
+		END
+	) p ON TRUE
+`
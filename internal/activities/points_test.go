@@ -0,0 +1,45 @@
+package activities_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPoints(t *testing.T) {
+	rules := []*activities.PointRule{
+		{MediaType: activities.ActivityMediaTypeVisualNovel, Unit: activities.MetricCharacters, Amount: 350, Points: 1},
+		{MediaType: activities.ActivityMediaTypeBook, Unit: activities.MetricPages, Amount: 1, Points: 1},
+		{MediaType: activities.ActivityMediaTypeBook, Unit: activities.PointUnitMinutes, Amount: 10, Points: 1},
+		{MediaType: "", Unit: activities.PointUnitMinutes, Amount: 1, Points: 0.5},
+	}
+
+	vn := activities.NewActivity()
+	vn.MediaType = ref.New(activities.ActivityMediaTypeVisualNovel)
+	vn.Duration = time.Hour
+	vn.SetMeta("characters", 7000)
+	assert.Equal(t, 20.0, activities.Points(rules, vn))
+
+	book := activities.NewActivity()
+	book.MediaType = ref.New(activities.ActivityMediaTypeBook)
+	book.Duration = 30 * time.Minute
+	book.SetMeta("pages", 12)
+	assert.Equal(t, 15.0, activities.Points(rules, book))
+
+	// anime has no rules of its own, so the catch-all rule applies
+	anime := activities.NewActivity()
+	anime.MediaType = ref.New(activities.ActivityMediaTypeAnime)
+	anime.Duration = 24 * time.Minute
+	assert.Equal(t, 12.0, activities.Points(rules, anime))
+
+	other := activities.NewActivity()
+	other.Duration = 10 * time.Minute
+	assert.Equal(t, 5.0, activities.Points(rules, other))
+
+	// without a catch-all rule, media types without rules earn nothing
+	assert.Zero(t, activities.Points(rules[:3], anime))
+	assert.Zero(t, activities.Points(nil, vn))
+}
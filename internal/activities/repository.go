@@ -2,6 +2,9 @@ package activities
 
 import (
 	"context"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -13,6 +16,90 @@ import (
 type MemberStats struct {
 	UserID        string
 	TotalDuration time.Duration
+	// Amount is the sum of the ranked meta value, zero when ranking by duration
+	Amount int64
+	// Rank is the member's place, shared by members with the same total
+	Rank int
+}
+
+// MemberPage is a page of a leaderboard
+type MemberPage struct {
+	Members     []*MemberStats
+	MemberCount int
+	PageCount   int
+	Page        int
+}
+
+// MemberRank is a member's place on a leaderboard
+type MemberRank struct {
+	MemberStats
+	MemberCount int
+	// Gap is how much more of the ranked metric, in nanoseconds when ranking by
+	// duration, the member needs to reach the next place, nil in first place
+	Gap *int64
+}
+
+// metrics that members can be ranked by, characters, pages and episodes are summed
+// from meta and points are worked out with the guild's point rules
+const (
+	MetricDuration   = "duration"
+	MetricCharacters = "characters"
+	MetricPages      = "pages"
+	MetricEpisodes   = "episodes"
+	MetricPoints     = "points"
+)
+
+// MemberFilter narrows down the activities counted towards a leaderboard,
+// empty fields match every activity and an empty metric ranks by duration
+type MemberFilter struct {
+	MediaType   string
+	PrimaryType string
+	Metric      string
+}
+
+// TypeTotal is the total duration of activities of one
+// primary and media type logged in a period
+type TypeTotal struct {
+	// Period is the first day (YYYY-MM-DD) of the period
+	Period      string
+	PrimaryType string
+	MediaType   *string
+	Duration    time.Duration
+}
+
+const (
+	PeriodDay  = "day"
+	PeriodWeek = "week"
+)
+
+// ActivityFilter narrows down the activities in a user's history, empty fields
+// match every activity and zero times leave the range open on that side
+type ActivityFilter struct {
+	MediaType   string
+	PrimaryType string
+	Start       time.Time
+	End         time.Time
+	// Query matches activities whose name contains it (case-insensitive)
+	Query string
+}
+
+// IsEmpty returns true if the filter matches every activity
+func (f ActivityFilter) IsEmpty() bool {
+	return f == ActivityFilter{}
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so s is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// timeOrNull returns nil for the zero time so it is stored as NULL
+func timeOrNull(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+
+	return &t
 }
 
 type UserActivityPage struct {
@@ -35,15 +122,29 @@ func NewActivityRepository(pool *pgxpool.Pool) *ActivityRepository {
 }
 
 func (r *ActivityRepository) Create(ctx context.Context, activity *Activity) error {
-	conn, err := r.pool.Acquire(ctx)
+	tx, err := r.pool.Begin(ctx)
 
 	if err != nil {
 		return err
 	}
 
-	defer conn.Release()
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	if err = r.CreateTx(ctx, tx, activity); err != nil {
+		return err
+	}
 
-	err = conn.QueryRow(
+	return tx.Commit(ctx)
+}
+
+// CreateTx creates the activity as part of tx
+func (r *ActivityRepository) CreateTx(ctx context.Context, tx pgx.Tx, activity *Activity) error {
+	// handlers only set the date when one is given by the user
+	if activity.Date.IsZero() {
+		activity.Date = time.Now()
+	}
+
+	return tx.QueryRow(
 		ctx,
 		`INSERT INTO activities (user_id, guild_id, name, primary_type, media_type, duration, date, meta)
 			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
@@ -57,8 +158,6 @@ func (r *ActivityRepository) Create(ctx context.Context, activity *Activity) err
 		activity.Date,
 		activity.Meta).
 		Scan(&activity.ID)
-
-	return err
 }
 
 func (r *ActivityRepository) ImportMany(ctx context.Context, as []*Activity) error {
@@ -108,15 +207,17 @@ func (r *ActivityRepository) ImportMany(ctx context.Context, as []*Activity) err
 	return err
 }
 
+// UndoImportByUserIDAndTimestamp removes the activities of the import, returning
+// how many were removed and the guilds they had been logged in
 func (r *ActivityRepository) UndoImportByUserIDAndTimestamp(
 	ctx context.Context,
 	userID string,
 	timestamp time.Time,
-) (int64, error) {
+) (removed int64, guildIDs []string, err error) {
 	conn, err := r.pool.Acquire(ctx)
 
 	if err != nil {
-		return 0, err
+		return
 	}
 
 	defer conn.Release()
@@ -127,10 +228,33 @@ func (r *ActivityRepository) UndoImportByUserIDAndTimestamp(
 		WHERE user_id = $1
 		AND imported_at = $2 AT TIME ZONE 'UTC'
 		AND deleted_at IS NULL
+		RETURNING guild_id
 	`
 
-	tag, err := conn.Exec(ctx, sql, userID, timestamp)
-	return tag.RowsAffected(), err
+	rows, err := conn.Query(ctx, sql, userID, timestamp)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var guildID *string
+
+		if err = rows.Scan(&guildID); err != nil {
+			return
+		}
+
+		removed++
+
+		if guildID != nil && !slices.Contains(guildIDs, *guildID) {
+			guildIDs = append(guildIDs, *guildID)
+		}
+	}
+
+	err = rows.Err()
+	return
 }
 
 func (r *ActivityRepository) GetRecentImportsByUserID(
@@ -292,12 +416,73 @@ func (r *ActivityRepository) GetTotalByUserIDGroupedByMonth(
 	return durations, nil
 }
 
+// GetTotalByUserIDGroupedByType returns the user's total duration for each primary and media type
+// in every day or week (PeriodDay or PeriodWeek) between start and end, in the user's timezone
+func (r *ActivityRepository) GetTotalByUserIDGroupedByType(
+	ctx context.Context,
+	userID, guildID string,
+	start, end time.Time,
+	period string,
+) ([]*TypeTotal, error) {
+	const query = `
+		SELECT
+			to_char(
+				date_trunc($5, activities.date at time zone COALESCE(u.timezone, g.timezone, 'UTC')),
+				'YYYY-MM-DD'
+			) AS period,
+			activities.primary_type,
+			activities.media_type,
+			SUM(activities.duration) AS total_duration
+		FROM activities
+		LEFT JOIN users u ON u.id = $1
+		LEFT JOIN guilds g ON g.id = $2
+		WHERE activities.user_id = $1
+		AND activities.date >= $3
+		AND activities.date <= $4
+		AND activities.deleted_at IS NULL
+		GROUP BY period, activities.primary_type, activities.media_type
+		ORDER BY period ASC
+	`
+
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, userID, guildID, start, end, period)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := make([]*TypeTotal, 0)
+
+	for rows.Next() {
+		total := &TypeTotal{}
+
+		if err := rows.Scan(&total.Period, &total.PrimaryType, &total.MediaType, &total.Duration); err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
 // Returns map of day (YYYY-MM-DD) to total duration
-// filling in missing days with 0 (string formatted according to user's timezone)
+// filling in missing days with 0 (string formatted according to user's timezone),
+// only counting activities of primaryType unless it is empty
 func (r *ActivityRepository) GetTotalByUserIDGroupedByDay(
 	ctx context.Context,
 	userID, guildID string,
 	start, end time.Time,
+	primaryType string,
 ) (orderedmap.Map[time.Duration], error) {
 	// day should be truncated to a string `YYYY-MM-DD` in the user's timezone
 	const query = `
@@ -321,6 +506,7 @@ func (r *ActivityRepository) GetTotalByUserIDGroupedByDay(
 			)
 			AND activities.user_id = $1
 			AND activities.deleted_at IS NULL
+			AND ($5::text = '' OR activities.primary_type::text = $5)
 		GROUP BY day
 		ORDER BY day ASC
 	`
@@ -333,7 +519,7 @@ func (r *ActivityRepository) GetTotalByUserIDGroupedByDay(
 
 	defer conn.Release()
 
-	rows, err := conn.Query(ctx, query, userID, guildID, start, end)
+	rows, err := conn.Query(ctx, query, userID, guildID, start, end, primaryType)
 
 	if err != nil {
 		return nil, err
@@ -529,10 +715,150 @@ func (r *ActivityRepository) GetAllByUserID(ctx context.Context, userID, guildID
 	return activities, nil
 }
 
+// GetByUserIDBetween returns the user's activities with a date in the range [start, end)
+func (r *ActivityRepository) GetByUserIDBetween(ctx context.Context, userID string, start, end time.Time) ([]*Activity, error) {
+	const query = `
+		SELECT id,
+			   user_id,
+			   guild_id,
+			   name,
+			   primary_type,
+			   media_type,
+			   duration,
+			   date,
+			   created_at,
+			   deleted_at,
+			   imported_at,
+			   meta
+		FROM activities
+		WHERE user_id = $1
+		AND date >= $2
+		AND date < $3
+		AND deleted_at IS NULL
+		ORDER BY date ASC
+	`
+
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, userID, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	activities := make([]*Activity, 0)
+
+	for rows.Next() {
+		var activity Activity
+		if err := rows.Scan(
+			&activity.ID,
+			&activity.UserID,
+			&activity.GuildID,
+			&activity.Name,
+			&activity.PrimaryType,
+			&activity.MediaType,
+			&activity.Duration,
+			&activity.Date,
+			&activity.CreatedAt,
+			&activity.DeletedAt,
+			&activity.ImportedAt,
+			&activity.Meta,
+		); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	return activities, nil
+}
+
+// GetByGuildIDBetween returns the activities logged in the guild within [start, end), oldest first
+func (r *ActivityRepository) GetByGuildIDBetween(ctx context.Context, guildID string, start, end time.Time) ([]*Activity, error) {
+	const query = `
+		SELECT id,
+			   user_id,
+			   guild_id,
+			   name,
+			   primary_type,
+			   media_type,
+			   duration,
+			   date,
+			   created_at,
+			   deleted_at,
+			   imported_at,
+			   meta
+		FROM activities
+		WHERE guild_id = $1
+		AND date >= $2
+		AND date < $3
+		AND deleted_at IS NULL
+		ORDER BY date ASC
+	`
+
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx, query, guildID, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	activities := make([]*Activity, 0)
+
+	for rows.Next() {
+		var activity Activity
+		if err := rows.Scan(
+			&activity.ID,
+			&activity.UserID,
+			&activity.GuildID,
+			&activity.Name,
+			&activity.PrimaryType,
+			&activity.MediaType,
+			&activity.Duration,
+			&activity.Date,
+			&activity.CreatedAt,
+			&activity.DeletedAt,
+			&activity.ImportedAt,
+			&activity.Meta,
+		); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	return activities, nil
+}
+
 func (r *ActivityRepository) PageByUserID(
 	ctx context.Context,
 	userID, guildID string,
 	limit, offset int,
+) (*UserActivityPage, error) {
+	return r.PageByUserIDFiltered(ctx, userID, guildID, ActivityFilter{}, limit, offset)
+}
+
+// PageByUserIDFiltered returns a page of the user's activities matching the filter, latest first
+func (r *ActivityRepository) PageByUserIDFiltered(
+	ctx context.Context,
+	userID, guildID string,
+	filter ActivityFilter,
+	limit, offset int,
 ) (*UserActivityPage, error) {
 	const query = `
 		SELECT activities.id,
@@ -554,6 +880,11 @@ func (r *ActivityRepository) PageByUserID(
 		LEFT JOIN guilds g ON activities.guild_id = $2
 		WHERE activities.user_id = $1
 		AND deleted_at IS NULL
+		AND ($5::text = '' OR media_type::text = $5)
+		AND ($6::text = '' OR primary_type::text = $6)
+		AND ($7::timestamptz IS NULL OR date >= $7)
+		AND ($8::timestamptz IS NULL OR date <= $8)
+		AND ($9::text = '' OR name ILIKE '%' || $9 || '%')
 		ORDER BY date DESC
 		LIMIT $3
 		OFFSET $4
@@ -567,7 +898,19 @@ func (r *ActivityRepository) PageByUserID(
 
 	defer conn.Release()
 
-	rows, err := conn.Query(ctx, query, userID, guildID, limit, offset)
+	rows, err := conn.Query(
+		ctx,
+		query,
+		userID,
+		guildID,
+		limit,
+		offset,
+		filter.MediaType,
+		filter.PrimaryType,
+		timeOrNull(filter.Start),
+		timeOrNull(filter.End),
+		escapeLike(filter.Query),
+	)
 
 	if err != nil {
 		return nil, err
@@ -603,6 +946,38 @@ func (r *ActivityRepository) PageByUserID(
 	return page, nil
 }
 
+func (r *ActivityRepository) Update(ctx context.Context, activity *Activity) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx, `
+		UPDATE activities
+		SET name = $1,
+			primary_type = $2,
+			media_type = $3,
+			duration = $4,
+			date = $5,
+			meta = $6
+		WHERE id = $7
+		AND deleted_at IS NULL
+	`,
+		activity.Name,
+		activity.PrimaryType,
+		activity.MediaType,
+		activity.Duration,
+		activity.Date,
+		activity.Meta,
+		activity.ID,
+	)
+
+	return err
+}
+
 func (r *ActivityRepository) DeleteByID(ctx context.Context, id uint64) error {
 	conn, err := r.pool.Acquire(ctx)
 
@@ -621,29 +996,110 @@ func (r *ActivityRepository) DeleteByID(ctx context.Context, id uint64) error {
 	return err
 }
 
-func (r *ActivityRepository) GetTopMembers(ctx context.Context, guildID string, limit int, start, end time.Time) ([]*MemberStats, error) {
-	members := make([]*MemberStats, 0)
+// rankedMembersQuery returns the start of a query ranking every member of guild $1 by the
+// filter's metric over their activities between $2 and $3 of media type $4 and primary type $5,
+// ending in a ranked table with the columns of MemberRank and the number of ranked members,
+// members with private activity being left out
+func rankedMembersQuery(filter MemberFilter) (string, error) {
+	amount := "0"
+	order := "total_duration"
+	// members without any of the meta value or points are left out
+	having := ""
+	join := ""
+
+	switch filter.Metric {
+	case MetricCharacters, MetricPages, MetricEpisodes:
+		amount = sumMetaNumber(filter.Metric)
+		order = "amount"
+		having = "HAVING " + amount + " > 0"
+	case MetricPoints:
+		amount = "ROUND(COALESCE(SUM(p.points), 0))::bigint"
+		order = "amount"
+		having = "HAVING " + amount + " > 0"
+		join = pointsJoin
+	case "", MetricDuration:
+	default:
+		return "", fmt.Errorf("unknown metric: %s", filter.Metric)
+	}
 
-	conn, err := r.pool.Acquire(ctx)
+	return `
+		WITH totals AS (
+			SELECT m.user_id, COALESCE(SUM(a.duration), 0)::bigint AS total_duration, ` + amount + ` AS amount
+			FROM guild_members m
+			JOIN activities a ON m.user_id = a.user_id
+			` + join + `
+			WHERE m.guild_id = $1
+			AND a.date >= $2
+			AND a.date <= $3
+			AND a.deleted_at IS NULL
+			AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = m.user_id AND u.privacy = 'private')
+			AND ($4::text = '' OR a.media_type::text = $4)
+			AND ($5::text = '' OR a.primary_type::text = $5)
+			GROUP BY m.user_id
+			` + having + `
+		), ranked AS (
+			SELECT
+				user_id,
+				total_duration,
+				amount,
+				RANK() OVER (ORDER BY ` + order + ` DESC) AS rank,
+				-- the lowest total above the member's is the next place up
+				MIN(` + order + `) OVER (
+					ORDER BY ` + order + ` ASC
+					RANGE BETWEEN 1 FOLLOWING AND UNBOUNDED FOLLOWING
+				) - ` + order + ` AS gap,
+				COUNT(*) OVER () AS member_count
+			FROM totals
+		)
+	`, nil
+}
+
+func (r *ActivityRepository) GetTopMembers(
+	ctx context.Context,
+	guildID string,
+	limit int,
+	start, end time.Time,
+	filter MemberFilter,
+) ([]*MemberStats, error) {
+	page, err := r.PageTopMembers(ctx, guildID, start, end, filter, limit, 0)
 
 	if err != nil {
 		return nil, err
 	}
 
-	defer conn.Release()
+	return page.Members, nil
+}
 
-	rows, err := conn.Query(ctx, `
-		SELECT m.user_id, COALESCE(SUM(a.duration), 0) AS total_duration
-		FROM guild_members m
-		LEFT JOIN activities a ON m.user_id = a.user_id
-		WHERE m.guild_id = $1
-		AND a.date >= $2
-		AND a.date <= $3
-		AND a.deleted_at IS NULL
-		GROUP BY m.user_id
-		ORDER BY total_duration DESC
-		LIMIT $4
-	`, guildID, start, end, limit)
+// PageTopMembers returns a page of the members of the guild ranked by the filter's metric
+func (r *ActivityRepository) PageTopMembers(
+	ctx context.Context,
+	guildID string,
+	start, end time.Time,
+	filter MemberFilter,
+	limit, offset int,
+) (*MemberPage, error) {
+	ranked, err := rankedMembersQuery(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := ranked + `
+		SELECT
+			user_id,
+			total_duration,
+			amount,
+			rank,
+			member_count,
+			CEIL(member_count / $6::float) AS page_count,
+			CEIL($7::float / $6::float) + 1 AS page
+		FROM ranked
+		ORDER BY rank ASC, user_id ASC
+		LIMIT $6
+		OFFSET $7
+	`
+
+	rows, err := r.pool.Query(ctx, query, guildID, start, end, filter.MediaType, filter.PrimaryType, limit, offset)
 
 	if err != nil {
 		return nil, err
@@ -651,18 +1107,67 @@ func (r *ActivityRepository) GetTopMembers(ctx context.Context, guildID string,
 
 	defer rows.Close()
 
+	page := &MemberPage{
+		Members: make([]*MemberStats, 0, limit),
+	}
+
 	for rows.Next() {
-		var member MemberStats
+		member := &MemberStats{}
+
 		if err := rows.Scan(
 			&member.UserID,
 			&member.TotalDuration,
+			&member.Amount,
+			&member.Rank,
+			&page.MemberCount,
+			&page.PageCount,
+			&page.Page,
 		); err != nil {
 			return nil, err
 		}
-		members = append(members, &member)
+
+		page.Members = append(page.Members, member)
+	}
+
+	return page, rows.Err()
+}
+
+// GetMemberRank returns the rank of a member of the guild by the filter's metric,
+// or pgx.ErrNoRows if they have no activities counted towards it
+func (r *ActivityRepository) GetMemberRank(
+	ctx context.Context,
+	guildID, userID string,
+	start, end time.Time,
+	filter MemberFilter,
+) (*MemberRank, error) {
+	ranked, err := rankedMembersQuery(filter)
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := ranked + `
+		SELECT user_id, total_duration, amount, rank, gap, member_count
+		FROM ranked
+		WHERE user_id = $6
+	`
+
+	member := &MemberRank{}
+
+	err = r.pool.QueryRow(ctx, query, guildID, start, end, filter.MediaType, filter.PrimaryType, userID).Scan(
+		&member.UserID,
+		&member.TotalDuration,
+		&member.Amount,
+		&member.Rank,
+		&member.Gap,
+		&member.MemberCount,
+	)
+
+	if err != nil {
+		return nil, err
 	}
 
-	return members, nil
+	return member, nil
 }
 
 func (r *ActivityRepository) GetAvgSpeedByMediaTypeAndUserID(ctx context.Context, mediaType, userID string, start, end time.Time) (float32, error) {
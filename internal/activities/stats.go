@@ -0,0 +1,456 @@
+package activities
+
+import (
+	"context"
+	"time"
+
+	"github.com/UTD-JLA/botsu/pkg/orderedmap"
+)
+
+// MediaKeyExpression identifies the work an activity is about by its AniDB or VNDB ID,
+// falling back to its name for activities without one
+const MediaKeyExpression = `COALESCE('anidb:' || (meta->>'anidb_id'), 'vndb:' || (meta->>'vndb_id'), name)`
+
+// UserSummary is the total of a user's activities in a time range
+type UserSummary struct {
+	Total time.Duration
+	Count int
+	// First and Last are the dates of the earliest and latest
+	// activities, nil if there are none
+	First *time.Time
+	Last  *time.Time
+}
+
+// DailyTotal is the total time logged on one day
+type DailyTotal struct {
+	Day   time.Time
+	Total time.Duration
+}
+
+// TitleStats is the total time spent on one work
+type TitleStats struct {
+	Key   string
+	Title string
+	Total time.Duration
+	Count int
+}
+
+// MediaStats is a user's total for one work, identified by its media key
+type MediaStats struct {
+	Key       string
+	Title     string
+	MediaType *string
+	Thumbnail *string
+	Total     time.Duration
+	Count     int
+	First     time.Time
+	Last      time.Time
+	// sums of the characters, pages and episodes meta
+	Characters int64
+	Pages      int64
+	Episodes   int64
+}
+
+func (r *ActivityRepository) GetSummaryByUserID(ctx context.Context, userID string, start, end time.Time) (*UserSummary, error) {
+	const query = `
+		SELECT COALESCE(SUM(duration), 0), COUNT(*), MIN(date), MAX(date)
+		FROM activities
+		WHERE user_id = $1
+		AND date >= $2
+		AND date <= $3
+		AND deleted_at IS NULL
+	`
+
+	summary := &UserSummary{}
+	err := r.pool.QueryRow(ctx, query, userID, start, end).Scan(&summary.Total, &summary.Count, &summary.First, &summary.Last)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
+
+// media_type is cast to text as an empty string is not a value of its enum
+const totalByMediaTypeQuery = `
+	SELECT COALESCE(media_type::text, ''), SUM(duration) AS total_duration
+	FROM activities
+	WHERE user_id = $1
+	AND date >= $2
+	AND date <= $3
+	AND deleted_at IS NULL
+	GROUP BY COALESCE(media_type::text, '')
+	ORDER BY total_duration DESC
+`
+
+// GetTotalByUserIDGroupedByMediaType returns the user's total for each media type, largest first,
+// activities without a media type are under the empty string
+func (r *ActivityRepository) GetTotalByUserIDGroupedByMediaType(
+	ctx context.Context,
+	userID string,
+	start, end time.Time,
+) (orderedmap.Map[time.Duration], error) {
+	rows, err := r.pool.Query(ctx, totalByMediaTypeQuery, userID, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := orderedmap.New[time.Duration]()
+
+	for rows.Next() {
+		var mediaType string
+		var duration time.Duration
+
+		if err := rows.Scan(&mediaType, &duration); err != nil {
+			return nil, err
+		}
+
+		totals.Set(mediaType, duration)
+	}
+
+	return totals, rows.Err()
+}
+
+// GetDailyTotalsByUserID returns the total time the user logged on each day with
+// activity from since onwards, in the user's timezone, with the earliest first
+func (r *ActivityRepository) GetDailyTotalsByUserID(ctx context.Context, userID, guildID string, since time.Time) ([]*DailyTotal, error) {
+	const query = `
+		SELECT
+			(activities.date AT TIME ZONE COALESCE(u.timezone, g.timezone, 'UTC'))::date AS day,
+			SUM(activities.duration) AS total_duration
+		FROM activities
+		LEFT JOIN users u ON u.id = $1
+		LEFT JOIN guilds g ON g.id = $2
+		WHERE activities.user_id = $1
+		AND activities.deleted_at IS NULL
+		AND (activities.date AT TIME ZONE COALESCE(u.timezone, g.timezone, 'UTC'))::date >= $3::date
+		GROUP BY day
+		ORDER BY day
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, guildID, since)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := make([]*DailyTotal, 0)
+
+	for rows.Next() {
+		total := &DailyTotal{}
+
+		if err := rows.Scan(&total.Day, &total.Total); err != nil {
+			return nil, err
+		}
+
+		totals = append(totals, total)
+	}
+
+	return totals, rows.Err()
+}
+
+// GetTopTitlesByUserID returns the works the user spent the most time on,
+// titled by the name of their latest activity
+func (r *ActivityRepository) GetTopTitlesByUserID(ctx context.Context, userID string, start, end time.Time, limit int) ([]*TitleStats, error) {
+	const query = `
+		SELECT
+			` + MediaKeyExpression + ` AS media_key,
+			(ARRAY_AGG(COALESCE(meta->>'title', name) ORDER BY date DESC))[1],
+			SUM(duration) AS total_duration,
+			COUNT(*)
+		FROM activities
+		WHERE user_id = $1
+		AND date >= $2
+		AND date <= $3
+		AND deleted_at IS NULL
+		GROUP BY media_key
+		ORDER BY total_duration DESC
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, start, end, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	titles := make([]*TitleStats, 0, limit)
+
+	for rows.Next() {
+		title := &TitleStats{}
+
+		if err := rows.Scan(&title.Key, &title.Title, &title.Total, &title.Count); err != nil {
+			return nil, err
+		}
+
+		titles = append(titles, title)
+	}
+
+	return titles, rows.Err()
+}
+
+// GetAvgSpeedByUserIDGroupedByMediaType returns the user's average speed from the
+// speed meta of their activities for each media type that has one
+func (r *ActivityRepository) GetAvgSpeedByUserIDGroupedByMediaType(ctx context.Context, userID string, start, end time.Time) (map[string]float64, error) {
+	const query = `
+		SELECT media_type, AVG((meta->'speed')::numeric)
+		FROM activities
+		WHERE user_id = $1
+		AND media_type IS NOT NULL
+		AND deleted_at IS NULL
+		AND date >= $2
+		AND date <= $3
+		AND meta->'speed' IS NOT NULL
+		AND jsonb_typeof(meta->'speed') = 'number'
+		GROUP BY media_type
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, start, end)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	speeds := make(map[string]float64)
+
+	for rows.Next() {
+		var mediaType string
+		var speed float64
+
+		if err := rows.Scan(&mediaType, &speed); err != nil {
+			return nil, err
+		}
+
+		speeds[mediaType] = speed
+	}
+
+	return speeds, rows.Err()
+}
+
+// GetTotalByUserIDGroupedByWeekday returns the user's total on each day of the week
+// in the user's timezone, indexed by time.Weekday
+func (r *ActivityRepository) GetTotalByUserIDGroupedByWeekday(
+	ctx context.Context,
+	userID, guildID string,
+	start, end time.Time,
+) (totals [7]time.Duration, err error) {
+	const query = `
+		SELECT
+			EXTRACT(DOW FROM activities.date AT TIME ZONE COALESCE(u.timezone, g.timezone, 'UTC'))::int AS weekday,
+			SUM(activities.duration)
+		FROM activities
+		LEFT JOIN users u ON u.id = $1
+		LEFT JOIN guilds g ON g.id = $2
+		WHERE activities.user_id = $1
+		AND activities.date >= $3
+		AND activities.date <= $4
+		AND activities.deleted_at IS NULL
+		GROUP BY weekday
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, guildID, start, end)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var weekday int
+		var duration time.Duration
+
+		if err = rows.Scan(&weekday, &duration); err != nil {
+			return
+		}
+
+		totals[weekday] = duration
+	}
+
+	err = rows.Err()
+	return
+}
+
+// sumMetaNumber sums a numeric meta value, ignoring activities where it is missing or not a number
+func sumMetaNumber(key string) string {
+	return `COALESCE(SUM(CASE WHEN jsonb_typeof(meta->'` + key + `') = 'number' THEN (meta->'` + key + `')::numeric END), 0)::bigint`
+}
+
+// GetMediaStatsByUserID returns the user's total for the work with the given media key
+// (see MediaKeyExpression), or pgx.ErrNoRows if the user has not logged it
+func (r *ActivityRepository) GetMediaStatsByUserID(ctx context.Context, userID, key string) (*MediaStats, error) {
+	query := `
+		SELECT
+			` + MediaKeyExpression + ` AS media_key,
+			(ARRAY_AGG(COALESCE(meta->>'title', name) ORDER BY date DESC))[1],
+			(ARRAY_AGG(media_type ORDER BY date DESC))[1],
+			(ARRAY_AGG(meta->>'thumbnail' ORDER BY date DESC) FILTER (WHERE meta->>'thumbnail' <> ''))[1],
+			SUM(duration),
+			COUNT(*),
+			MIN(date),
+			MAX(date),
+			` + sumMetaNumber("characters") + `,
+			` + sumMetaNumber("pages") + `,
+			` + sumMetaNumber("episodes") + `
+		FROM activities
+		WHERE user_id = $1
+		AND ` + MediaKeyExpression + ` = $2
+		AND deleted_at IS NULL
+		GROUP BY media_key
+	`
+
+	stats := &MediaStats{}
+
+	err := r.pool.QueryRow(ctx, query, userID, key).Scan(
+		&stats.Key,
+		&stats.Title,
+		&stats.MediaType,
+		&stats.Thumbnail,
+		&stats.Total,
+		&stats.Count,
+		&stats.First,
+		&stats.Last,
+		&stats.Characters,
+		&stats.Pages,
+		&stats.Episodes,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// GetMediaMembersByGuildID returns the members of the guild who logged the work
+// with the given media key, by their total time on it, largest first,
+// leaving out members who do not share their titles
+func (r *ActivityRepository) GetMediaMembersByGuildID(ctx context.Context, guildID, key string, limit int) ([]*MemberStats, error) {
+	query := `
+		SELECT m.user_id, SUM(a.duration) AS total_duration
+		FROM guild_members m
+		JOIN activities a ON m.user_id = a.user_id
+		WHERE m.guild_id = $1
+		AND ` + MediaKeyExpression + ` = $2
+		AND a.deleted_at IS NULL
+		AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = m.user_id AND u.privacy <> 'public')
+		GROUP BY m.user_id
+		ORDER BY total_duration DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, guildID, key, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	members := make([]*MemberStats, 0, limit)
+
+	for rows.Next() {
+		member := &MemberStats{}
+
+		if err := rows.Scan(&member.UserID, &member.TotalDuration); err != nil {
+			return nil, err
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetLifetimeTotalsByGuildID returns the total time every member of the guild has
+// logged in it, or only the member with userID if it is not empty. Private members are
+// counted too, as the totals are not shown to anyone
+func (r *ActivityRepository) GetLifetimeTotalsByGuildID(ctx context.Context, guildID, userID string) (map[string]time.Duration, error) {
+	query := `
+		SELECT
+			m.user_id,
+			COALESCE(SUM(a.duration), 0)::bigint AS total_duration
+		FROM guild_members m
+		LEFT JOIN activities a ON a.user_id = m.user_id AND a.guild_id = m.guild_id AND a.deleted_at IS NULL
+		WHERE m.guild_id = $1
+		AND ($2::text = '' OR m.user_id = $2)
+		GROUP BY m.user_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, guildID, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	totals := make(map[string]time.Duration)
+
+	for rows.Next() {
+		var memberID string
+		var total time.Duration
+
+		if err := rows.Scan(&memberID, &total); err != nil {
+			return nil, err
+		}
+
+		totals[memberID] = total
+	}
+
+	return totals, rows.Err()
+}
+
+// SearchTitlesByUserID returns the works the user logged whose latest
+// title contains the query, most recently logged first
+func (r *ActivityRepository) SearchTitlesByUserID(ctx context.Context, userID, query string, limit int) ([]*TitleStats, error) {
+	const sql = `
+		SELECT media_key, title, total_duration, count
+		FROM (
+			SELECT
+				` + MediaKeyExpression + ` AS media_key,
+				(ARRAY_AGG(COALESCE(meta->>'title', name) ORDER BY date DESC))[1] AS title,
+				SUM(duration) AS total_duration,
+				COUNT(*) AS count,
+				MAX(date) AS last_date
+			FROM activities
+			WHERE user_id = $1
+			AND deleted_at IS NULL
+			GROUP BY media_key
+		) AS titles
+		WHERE title ILIKE '%' || $2 || '%'
+		ORDER BY last_date DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, sql, userID, query, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	titles := make([]*TitleStats, 0, limit)
+
+	for rows.Next() {
+		title := &TitleStats{}
+
+		if err := rows.Scan(&title.Key, &title.Title, &title.Total, &title.Count); err != nil {
+			return nil, err
+		}
+
+		titles = append(titles, title)
+	}
+
+	return titles, rows.Err()
+}
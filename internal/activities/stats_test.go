@@ -0,0 +1,15 @@
+package activities_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTotalByMediaTypeQueryCastsMediaType(t *testing.T) {
+	// COALESCE(media_type, '') coerces '' to the activity_media_type enum and fails
+	assert.NotContains(t, activities.TotalByMediaTypeQuery, "COALESCE(media_type, '')")
+	assert.Equal(t, 2, strings.Count(activities.TotalByMediaTypeQuery, "COALESCE(media_type::text, '')"))
+}
@@ -138,6 +138,11 @@ func (b *Bot) CollectSingleComponentInteraction(
 	return b.globalComponentCollector.CollectOnce(ctx, msg.ID, filter)
 }
 
+// Session returns the bot's session, nil until Login is called
+func (b *Bot) Session() *discordgo.Session {
+	return b.session
+}
+
 func (b *Bot) AddCommand(data *discordgo.ApplicationCommand, cmd CommandHandler) {
 	b.logger.Debug("Adding command", slog.String("command_name", data.Name))
 	b.commands.Add(data, cmd)
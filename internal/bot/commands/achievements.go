@@ -0,0 +1,129 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/achievements"
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+)
+
+var AchievementsCommandData = &discordgo.ApplicationCommand{
+	Name:        "achievements",
+	Description: "View your unlocked and remaining achievements",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Name:        "user",
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Description: "The user to view the achievements of (defaults to yourself).",
+			Required:    false,
+		},
+	},
+}
+
+type AchievementsCommand struct {
+	u            *users.UserRepository
+	streaks      *users.StreakService
+	achievements *achievements.AchievementService
+}
+
+func NewAchievementsCommand(u *users.UserRepository, streaks *users.StreakService, as *achievements.AchievementService) *AchievementsCommand {
+	return &AchievementsCommand{u: u, streaks: streaks, achievements: as}
+}
+
+func newAchievementsUnlockedEmbed(a *activities.Activity, unlocked []*achievements.Achievement) *discordutil.EmbedBuilder {
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Achievements unlocked!").
+		SetColor(discordutil.ColorSuccess).
+		SetTimestamp(time.Now()).
+		SetFooter(fmt.Sprintf("Activity ID: %d", a.ID), "").
+		SetDescription("You have unlocked the following achievements:")
+
+	for _, achievement := range unlocked {
+		embed.AddField("🏆 "+achievement.Name, achievement.Description, false)
+	}
+
+	return embed
+}
+
+func (c *AchievementsCommand) Handle(ctx *bot.InteractionContext) error {
+	if err := ctx.DeferResponse(); err != nil {
+		return err
+	}
+
+	i := ctx.Interaction()
+	user := discordutil.GetUserOptionOrDefault(ctx.Options(), "user", discordutil.GetInteractionUser(i), ctx.Session())
+
+	if user.ID != discordutil.GetInteractionUser(i).ID {
+		privacy, err := c.u.GetPrivacy(ctx.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		if !privacy.AllowsTotals(isResolvedGuildMember(i, user.ID)) {
+			return followupHidden(ctx, user, privacy)
+		}
+	}
+
+	// activities that were imported do not unlock achievements until now
+	if _, err := c.streaks.Update(ctx.Context(), user.ID, i.GuildID); err != nil {
+		return err
+	}
+
+	if _, err := c.achievements.Check(ctx.Context(), user.ID, i.GuildID); err != nil {
+		return err
+	}
+
+	unlocks, err := c.achievements.FindByUserID(ctx.Context(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	unlockedAt := make(map[string]time.Time, len(unlocks))
+	for _, unlock := range unlocks {
+		unlockedAt[unlock.AchievementID] = unlock.UnlockedAt
+	}
+
+	var unlocked, locked strings.Builder
+	count := 0
+
+	for _, achievement := range achievements.Registry {
+		if at, ok := unlockedAt[achievement.ID]; ok {
+			count++
+			fmt.Fprintf(&unlocked, "🏆 **%s**: %s <t:%d:d>\n", achievement.Name, achievement.Description, at.Unix())
+		} else {
+			fmt.Fprintf(&locked, "🔒 **%s**: %s\n", achievement.Name, achievement.Description)
+		}
+	}
+
+	var description strings.Builder
+
+	if unlocked.Len() > 0 {
+		description.WriteString(unlocked.String())
+	} else {
+		description.WriteString("No achievements unlocked yet!\n")
+	}
+
+	if locked.Len() > 0 {
+		description.WriteString("\n")
+		description.WriteString(locked.String())
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Achievements of %s", user.Username)).
+		SetThumbnail(user.AvatarURL("")).
+		SetColor(discordutil.ColorPrimary).
+		SetDescription(description.String()).
+		SetFooter(fmt.Sprintf("%d of %d unlocked", count, len(achievements.Registry)), "")
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	}, false)
+
+	return err
+}
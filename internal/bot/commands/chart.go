@@ -2,22 +2,16 @@ package commands
 
 import (
 	"bytes"
-	_ "embed"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"image/color"
-	"io"
 	"math"
-	"net/http"
-	"net/url"
-	"text/template"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
 	"github.com/UTD-JLA/botsu/internal/guilds"
 	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/chart"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
 	"github.com/UTD-JLA/botsu/pkg/orderedmap"
 	"github.com/bwmarrin/discordgo"
@@ -34,6 +28,108 @@ var ChartCommandData = &discordgo.ApplicationCommand{
 			Name:        "duration",
 			Description: "View a chart of your daily activity duration",
 			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "The type of chart to view",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "bar",
+							Value: ChartTypeBar,
+						},
+						{
+							Name:  "line",
+							Value: ChartTypeLine,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "start",
+					Description: "The start date of the chart",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "end",
+					Description: "The end date of the chart",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "heatmap",
+			Description: "View a calendar of the days you were active (default past year)",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "activity-type",
+					Description: "Only show one type of activity",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Reading",
+							Value: activities.ActivityImmersionTypeReading,
+						},
+						{
+							Name:  "Listening",
+							Value: activities.ActivityImmersionTypeListening,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "start",
+					Description: "The start date of the chart",
+					Required:    false,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "end",
+					Description: "The end date of the chart",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "breakdown",
+			Description: "View how your time is split between types of media",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "group-by",
+					Description: "What to split your time by (default media type)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Media Type",
+							Value: "media-type",
+						},
+						{
+							Name:  "Activity Type",
+							Value: "activity-type",
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "period",
+					Description: "The period of each bar (default daily for up to a month, otherwise weekly)",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Daily",
+							Value: activities.PeriodDay,
+						},
+						{
+							Name:  "Weekly",
+							Value: activities.PeriodWeek,
+						},
+					},
+				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "start",
@@ -61,11 +157,11 @@ var ChartCommandData = &discordgo.ApplicationCommand{
 					Choices: []*discordgo.ApplicationCommandOptionChoice{
 						{
 							Name:  "pie",
-							Value: "pie",
+							Value: ChartTypePie,
 						},
 						{
 							Name:  "bar",
-							Value: "bar",
+							Value: ChartTypeBar,
 						},
 					},
 				},
@@ -86,100 +182,75 @@ var ChartCommandData = &discordgo.ApplicationCommand{
 	},
 }
 
-type ChartCommand struct {
-	ar *activities.ActivityRepository
-	ur *users.UserRepository
-	gr *guilds.GuildRepository
-}
-
-func NewChartCommand(ar *activities.ActivityRepository, ur *users.UserRepository, gr *guilds.GuildRepository) *ChartCommand {
-	return &ChartCommand{ar: ar, ur: ur, gr: gr}
-}
-
-var quickChartURL = url.URL{
-	Scheme: "https",
-	Host:   "quickchart.io",
-	Path:   "/chart",
-}
-
-//go:embed chart_body.json.tmpl
-var barBodyTemplateFile string
-
-//go:embed chart_body_channel_pie.json.tmpl
-var channelPieBodyTemplateFile string
-
-var barBodyTemplate = template.Must(template.New("body").Parse(barBodyTemplateFile))
-var channelPieBodyTemplate = template.Must(template.New("body").Parse(channelPieBodyTemplateFile))
-
-type barRequestBody struct {
-	Values         string
-	Labels         string
-	Color          string
-	SecondaryColor string
-	Horizontal     int
-	ShowHorizontal bool
-}
-
-type pieRequestBody struct {
-	Values string
-	Labels string
-}
-
-func colorAsHex(c color.Color) string {
-	r, g, b, _ := c.RGBA()
-	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
-}
-
-func getQuickChartBarBody(xValues []string, yValues []float64, yBar int) (*bytes.Buffer, error) {
-	buffer := bytes.Buffer{}
-
-	values, _ := json.Marshal(yValues)
-	labels, _ := json.Marshal(xValues)
-
-	err := barBodyTemplate.Execute(&buffer, barRequestBody{
-		Values:         string(values),
-		Labels:         string(labels),
-		Color:          fmt.Sprintf("\"%s\"", colorAsHex(discordutil.ColorSecondary)),
-		SecondaryColor: fmt.Sprintf("\"%s\"", colorAsHex(discordutil.ColorPrimary)),
-		Horizontal:     yBar,
-		ShowHorizontal: yBar != 0,
-	})
+const (
+	// number of days shown by a heatmap without a start date
+	heatmapDefaultDays = 365
+	heatmapMaxDays     = 3 * 366
+	// breakdowns default to daily bars for ranges up to this many days
+	breakdownMaxAutoDailyDays = 31
+	breakdownMaxDailyDays     = 92
+	breakdownMaxDays          = 366
+)
 
-	if err != nil {
-		return nil, err
+// order of the categories of breakdowns, keeping their colors the same between charts
+var (
+	breakdownMediaTypes = []string{
+		activities.ActivityMediaTypeAnime,
+		activities.ActivityMediaTypeManga,
+		activities.ActivityMediaTypeVisualNovel,
+		activities.ActivityMediaTypeBook,
+		activities.ActivityMediaTypeVideo,
+		"",
 	}
+	breakdownActivityTypes = []string{
+		activities.ActivityImmersionTypeReading,
+		activities.ActivityImmersionTypeListening,
+	}
+)
 
-	compactBuffer := bytes.Buffer{}
-
-	if err = json.Compact(&compactBuffer, buffer.Bytes()); err != nil {
-		return nil, err
+// mediaTypeName returns the display name of a media type, or Other if it is nil or unknown
+func mediaTypeName(mediaType *string) string {
+	if mediaType == nil {
+		return "Other"
 	}
 
-	return &compactBuffer, nil
+	switch *mediaType {
+	case activities.ActivityMediaTypeAnime:
+		return "Anime"
+	case activities.ActivityMediaTypeManga:
+		return "Manga"
+	case activities.ActivityMediaTypeVisualNovel:
+		return "Visual Novel"
+	case activities.ActivityMediaTypeBook:
+		return "Book"
+	case activities.ActivityMediaTypeVideo:
+		return "Video"
+	default:
+		return "Other"
+	}
 }
 
-func getQuickChartChannelPieBody(labels []string, values []float64) (*bytes.Buffer, error) {
-	buffer := bytes.Buffer{}
-
-	valuesJSON, _ := json.Marshal(values)
-	labelsJSON, _ := json.Marshal(labels)
-
-	err := channelPieBodyTemplate.Execute(&buffer, pieRequestBody{
-		Values: string(valuesJSON),
-		Labels: string(labelsJSON),
-	})
-
-	if err != nil {
-		return nil, err
+// activityTypeName returns the display name of a primary type
+func activityTypeName(activityType string) string {
+	switch activityType {
+	case activities.ActivityImmersionTypeReading:
+		return "Reading"
+	case activities.ActivityImmersionTypeListening:
+		return "Listening"
+	default:
+		return activityType
 	}
+}
 
-	compactBuffer := bytes.Buffer{}
-
-	if err = json.Compact(&compactBuffer, buffer.Bytes()); err != nil {
-		return nil, err
-	}
+type ChartCommand struct {
+	ar       *activities.ActivityRepository
+	ur       *users.UserRepository
+	gr       *guilds.GuildRepository
+	renderer ChartRenderer
+}
 
-	return &compactBuffer, nil
+func NewChartCommand(ar *activities.ActivityRepository, ur *users.UserRepository, gr *guilds.GuildRepository, renderer ChartRenderer) *ChartCommand {
+	return &ChartCommand{ar: ar, ur: ur, gr: gr, renderer: renderer}
 }
 
 func (c *ChartCommand) handleYoutubeChannel(ctx *bot.InteractionContext, user *users.User, start, end carbon.Carbon, chartType string) error {
@@ -216,37 +287,16 @@ func (c *ChartCommand) handleYoutubeChannel(ctx *bot.InteractionContext, user *u
 		values[i] = math.Round(v)
 	}
 
-	var reqBody *bytes.Buffer
+	var image *bytes.Buffer
 
-	if chartType != "pie" {
-		reqBody, err = getQuickChartBarBody(keys, values, 0)
+	if chartType != ChartTypePie {
+		image, err = c.renderer.Render(ctx.ResponseContext(), ChartTypeBar, keys, values, 0)
 	} else {
-		reqBody, err = getQuickChartChannelPieBody(keys, values)
+		image, err = c.renderer.RenderPie(ctx.ResponseContext(), keys, values)
 	}
 
 	if err != nil {
-		return err
-	}
-
-	req := http.Request{
-		Method: http.MethodPost,
-		URL:    &quickChartURL,
-		Header: http.Header{
-			"Content-Type": []string{"application/json"},
-		},
-		Body: io.NopCloser(reqBody),
-	}
-
-	resp, err := http.DefaultClient.Do(&req)
-
-	if err != nil {
-		return err
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("failed to generate chart")
+		return fmt.Errorf("failed to render chart: %w", err)
 	}
 
 	description := fmt.Sprintf(
@@ -274,8 +324,262 @@ func (c *ChartCommand) handleYoutubeChannel(ctx *bot.InteractionContext, user *u
 		Files: []*discordgo.File{
 			{
 				Name:        "chart.png",
-				ContentType: resp.Header.Get("Content-Type"),
-				Reader:      resp.Body,
+				ContentType: "image/png",
+				Reader:      image,
+			},
+		},
+	})
+}
+
+// dateOnly returns midnight UTC of t's date, so the date
+// is unchanged when the database converts it to a date
+func dateOnly(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func (c *ChartCommand) handleHeatmap(ctx *bot.InteractionContext, user *users.User, start, end carbon.Carbon, activityType string) error {
+	if end.DiffAbsInDays(start) >= heatmapMaxDays {
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "You can only view up to 3 years of activity in a heatmap.",
+		})
+	}
+
+	startDate := dateOnly(start.ToStdTime())
+
+	dailyDurations, err := c.ar.GetTotalByUserIDGroupedByDay(
+		ctx.ResponseContext(),
+		user.ID,
+		ctx.Interaction().GuildID,
+		startDate,
+		dateOnly(end.ToStdTime()),
+		activityType,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	values := make([]float64, 0, dailyDurations.Len())
+	totalMinutes := 0.0
+	activeDays := 0
+	run, longestRun := 0, 0
+
+	for _, k := range dailyDurations.Keys() {
+		v, _ := dailyDurations.Get(k)
+		values = append(values, v.Minutes())
+		totalMinutes += v.Minutes()
+
+		if v > 0 {
+			activeDays++
+			run++
+			longestRun = max(longestRun, run)
+		} else {
+			run = 0
+		}
+	}
+
+	image, err := c.renderer.RenderHeatmap(ctx.ResponseContext(), startDate, values)
+
+	if err != nil {
+		return fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	title := "Activity Calendar"
+
+	switch activityType {
+	case activities.ActivityImmersionTypeReading:
+		title = "Reading Calendar"
+	case activities.ActivityImmersionTypeListening:
+		title = "Listening Calendar"
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(title).
+		SetDescription(fmt.Sprintf("Here is your activity from <t:%d:D> to <t:%d:D>", start.Timestamp(), end.Timestamp())).
+		SetColor(discordutil.ColorPrimary).
+		SetImage("attachment://chart.png").
+		AddField("Total", fmt.Sprintf("%.0f minutes", math.Round(totalMinutes)), true).
+		AddField("Active Days", fmt.Sprintf("%d / %d", activeDays, len(values)), true).
+		AddField("Longest Run of Active Days", fmt.Sprintf("%d days", longestRun), true)
+
+	return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		Files: []*discordgo.File{
+			{
+				Name:        "chart.png",
+				ContentType: "image/png",
+				Reader:      image,
+			},
+		},
+	})
+}
+
+// breakdownPeriods returns the first day of each period between start and end
+func breakdownPeriods(start, end time.Time, period string) []string {
+	step := 1
+	if period == activities.PeriodWeek {
+		step = 7
+		// weeks start on monday, matching date_trunc
+		start = start.AddDate(0, 0, -(int(start.Weekday())+6)%7)
+	}
+
+	periods := make([]string, 0)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, step) {
+		periods = append(periods, day.Format(time.DateOnly))
+	}
+
+	return periods
+}
+
+func (c *ChartCommand) handleBreakdown(ctx *bot.InteractionContext, user *users.User, start, end carbon.Carbon, groupBy, period string) error {
+	days := end.DiffAbsInDays(start)
+
+	if days >= breakdownMaxDays {
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "You can only view up to a year of activity in a breakdown.",
+		})
+	}
+
+	if period == "" {
+		period = activities.PeriodWeek
+		if days < breakdownMaxAutoDailyDays {
+			period = activities.PeriodDay
+		}
+	} else if period == activities.PeriodDay && days >= breakdownMaxDailyDays {
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Daily breakdowns can only show up to %d days, try a weekly breakdown instead.", breakdownMaxDailyDays),
+		})
+	}
+
+	totals, err := c.ar.GetTotalByUserIDGroupedByType(
+		ctx.ResponseContext(),
+		user.ID,
+		ctx.Interaction().GuildID,
+		start.ToStdTime(),
+		end.ToStdTime(),
+		period,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	labels := breakdownPeriods(dateOnly(start.ToStdTime()), dateOnly(end.ToStdTime()), period)
+	periodIndex := make(map[string]int, len(labels))
+	for i, label := range labels {
+		periodIndex[label] = i
+	}
+
+	categories := breakdownMediaTypes
+	if groupBy == "activity-type" {
+		categories = breakdownActivityTypes
+	}
+
+	minutes := make(map[string][]float64, len(categories))
+	for _, category := range categories {
+		minutes[category] = make([]float64, len(labels))
+	}
+
+	for _, total := range totals {
+		category := total.PrimaryType
+
+		if groupBy != "activity-type" {
+			category = ""
+			if total.MediaType != nil && mediaTypeName(total.MediaType) != "Other" {
+				category = *total.MediaType
+			}
+		}
+
+		i, ok := periodIndex[total.Period]
+		if values := minutes[category]; ok && values != nil {
+			values[i] += total.Duration.Minutes()
+		}
+	}
+
+	series := make([]chart.Series, 0, len(categories))
+	pieValues := make([]float64, 0, len(categories))
+	totalMinutes := 0.0
+
+	for _, category := range categories {
+		categoryMinutes := 0.0
+		for i, v := range minutes[category] {
+			minutes[category][i] = math.Round(v)
+			categoryMinutes += v
+		}
+
+		if math.Round(categoryMinutes) == 0 {
+			continue
+		}
+
+		name := activityTypeName(category)
+		if groupBy != "activity-type" {
+			name = mediaTypeName(&category)
+		}
+
+		series = append(series, chart.Series{
+			Name:   name,
+			Values: minutes[category],
+			Color:  chart.DefaultPalette[len(series)%len(chart.DefaultPalette)],
+		})
+
+		pieValues = append(pieValues, math.Round(categoryMinutes))
+		totalMinutes += categoryMinutes
+	}
+
+	if len(series) == 0 {
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "You have no activity in this time frame!",
+		})
+	}
+
+	barImage, err := c.renderer.RenderStacked(ctx.ResponseContext(), labels, series)
+
+	if err != nil {
+		return fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	pieLabels := make([]string, len(series))
+	for i, s := range series {
+		pieLabels[i] = s.Name
+	}
+
+	pieImage, err := c.renderer.RenderPie(ctx.ResponseContext(), pieLabels, pieValues)
+
+	if err != nil {
+		return fmt.Errorf("failed to render chart: %w", err)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Activity Breakdown").
+		SetDescription(fmt.Sprintf(
+			"Here is how your time was split from <t:%d:D> to <t:%d:D>. You logged a total of **%.0f minutes**.",
+			start.Timestamp(),
+			end.Timestamp(),
+			math.Round(totalMinutes),
+		)).
+		SetColor(discordutil.ColorPrimary).
+		SetImage("attachment://breakdown.png")
+
+	for i, s := range series {
+		embed.AddField(s.Name, fmt.Sprintf("%.0f minutes (%.0f%%)", pieValues[i], pieValues[i]/totalMinutes*100), true)
+	}
+
+	pieEmbed := discordutil.NewEmbedBuilder().
+		SetColor(discordutil.ColorPrimary).
+		SetImage("attachment://breakdown_pie.png")
+
+	return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed, pieEmbed.MessageEmbed},
+		Files: []*discordgo.File{
+			{
+				Name:        "breakdown.png",
+				ContentType: "image/png",
+				Reader:      barImage,
+			},
+			{
+				Name:        "breakdown_pie.png",
+				ContentType: "image/png",
+				Reader:      pieImage,
 			},
 		},
 	})
@@ -318,6 +622,10 @@ func (c *ChartCommand) Handle(ctx *bot.InteractionContext) error {
 	}
 
 	subcommand := ctx.Options()[0]
+
+	if subcommand.Name == "heatmap" {
+		start = carbon.Now(timezone).SubDays(heatmapDefaultDays - 1).StartOfDay()
+	}
 	startInput := discordutil.GetStringOption(subcommand.Options, "start")
 	endInput := discordutil.GetStringOption(subcommand.Options, "end")
 	customTimeframe := startInput != nil || endInput != nil
@@ -345,8 +653,21 @@ func (c *ChartCommand) Handle(ctx *bot.InteractionContext) error {
 		start, end = end, start
 	}
 
+	if subcommand.Name == "heatmap" {
+		activityType := discordutil.GetStringOptionOrDefault(subcommand.Options, "activity-type", "")
+
+		return c.handleHeatmap(ctx, user, start, end, activityType)
+	}
+
+	if subcommand.Name == "breakdown" {
+		groupBy := discordutil.GetStringOptionOrDefault(subcommand.Options, "group-by", "media-type")
+		period := discordutil.GetStringOptionOrDefault(subcommand.Options, "period", "")
+
+		return c.handleBreakdown(ctx, user, start, end, groupBy, period)
+	}
+
 	if subcommand.Name == "youtube-channel" {
-		chartType := discordutil.GetStringOptionOrDefault(subcommand.Options, "type", "pie")
+		chartType := discordutil.GetStringOptionOrDefault(subcommand.Options, "type", ChartTypePie)
 
 		return c.handleYoutubeChannel(ctx, user, start, end, chartType)
 	}
@@ -378,6 +699,7 @@ func (c *ChartCommand) Handle(ctx *bot.InteractionContext) error {
 			ctx.Interaction().GuildID,
 			start.ToStdTime(),
 			end.ToStdTime(),
+			"",
 		)
 	}
 
@@ -408,31 +730,11 @@ func (c *ChartCommand) Handle(ctx *bot.InteractionContext) error {
 		goal = 0
 	}
 
-	reqBody, err := getQuickChartBarBody(dailyDurations.Keys(), values, goal)
-
-	if err != nil {
-		return err
-	}
-
-	req := http.Request{
-		Method: http.MethodPost,
-		URL:    &quickChartURL,
-		Header: http.Header{
-			"Content-Type": []string{"application/json"},
-		},
-		Body: io.NopCloser(reqBody),
-	}
-
-	resp, err := http.DefaultClient.Do(&req)
+	chartType := discordutil.GetStringOptionOrDefault(subcommand.Options, "type", ChartTypeBar)
+	image, err := c.renderer.Render(ctx.ResponseContext(), chartType, dailyDurations.Keys(), values, goal)
 
 	if err != nil {
-		return err
-	}
-
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return errors.New("failed to generate chart")
+		return fmt.Errorf("failed to render chart: %w", err)
 	}
 
 	embed := discordutil.NewEmbedBuilder().
@@ -452,8 +754,8 @@ func (c *ChartCommand) Handle(ctx *bot.InteractionContext) error {
 		Files: []*discordgo.File{
 			{
 				Name:        "chart.png",
-				ContentType: resp.Header.Get("Content-Type"),
-				Reader:      resp.Body,
+				ContentType: "image/png",
+				Reader:      image,
 			},
 		},
 	})
@@ -0,0 +1,365 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/UTD-JLA/botsu/pkg/chart"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+)
+
+const (
+	ChartRendererLocal      = "local"
+	ChartRendererQuickChart = "quickchart"
+)
+
+const (
+	ChartTypeBar  = "bar"
+	ChartTypeLine = "line"
+	ChartTypePie  = "pie"
+)
+
+// ChartRenderer draws the charts attached to chart responses as PNG images
+type ChartRenderer interface {
+	// Render draws a bar or line chart with a dashed line at goal if it is non-zero
+	Render(ctx context.Context, chartType string, labels []string, values []float64, goal int) (*bytes.Buffer, error)
+	// RenderStacked draws a bar chart with the bars of each series stacked on top of each other
+	RenderStacked(ctx context.Context, labels []string, series []chart.Series) (*bytes.Buffer, error)
+	// RenderPie draws a pie chart with a legend of the labels
+	RenderPie(ctx context.Context, labels []string, values []float64) (*bytes.Buffer, error)
+	// RenderHeatmap draws a calendar with a cell for each value, one for each day from start
+	RenderHeatmap(ctx context.Context, start time.Time, values []float64) (*bytes.Buffer, error)
+	// RenderCard draws a card with a title and a grid of stats
+	RenderCard(ctx context.Context, title, subtitle string, stats []chart.CardStat) (*bytes.Buffer, error)
+}
+
+// NewChartRenderer returns the renderer with the given name,
+// either ChartRendererLocal or ChartRendererQuickChart
+func NewChartRenderer(name string) (ChartRenderer, error) {
+	switch name {
+	case ChartRendererLocal:
+		return &LocalChartRenderer{}, nil
+	case ChartRendererQuickChart:
+		return NewQuickChartRenderer(), nil
+	default:
+		return nil, fmt.Errorf("unknown chart renderer: %s", name)
+	}
+}
+
+func colorAsHex(c color.Color) string {
+	r, g, b, _ := c.RGBA()
+	return fmt.Sprintf("#%02x%02x%02x", r>>8, g>>8, b>>8)
+}
+
+// LocalChartRenderer draws charts without any external service
+type LocalChartRenderer struct{}
+
+func (r *LocalChartRenderer) Render(_ context.Context, chartType string, labels []string, values []float64, goal int) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+	series := []chart.Series{{Values: values, Color: discordutil.ColorSecondary}}
+
+	var err error
+
+	if chartType == ChartTypeLine {
+		err = (&chart.LineChart{
+			Width:          500,
+			Height:         300,
+			Theme:          chart.DarkTheme,
+			Labels:         labels,
+			Series:         series,
+			Threshold:      float64(goal),
+			ThresholdColor: discordutil.ColorPrimary,
+		}).Render(buffer)
+	} else {
+		err = (&chart.BarChart{
+			Width:          500,
+			Height:         300,
+			Theme:          chart.DarkTheme,
+			Labels:         labels,
+			Series:         series,
+			Threshold:      float64(goal),
+			ThresholdColor: discordutil.ColorPrimary,
+		}).Render(buffer)
+	}
+
+	return buffer, err
+}
+
+func (r *LocalChartRenderer) RenderStacked(_ context.Context, labels []string, series []chart.Series) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+
+	err := (&chart.BarChart{
+		Width:   500,
+		Height:  300,
+		Theme:   chart.DarkTheme,
+		Labels:  labels,
+		Series:  series,
+		Stacked: true,
+	}).Render(buffer)
+
+	return buffer, err
+}
+
+func (r *LocalChartRenderer) RenderPie(_ context.Context, labels []string, values []float64) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+
+	err := (&chart.PieChart{
+		Width:  600,
+		Height: 500,
+		Labels: labels,
+		Values: values,
+	}).Render(buffer)
+
+	return buffer, err
+}
+
+func (r *LocalChartRenderer) RenderHeatmap(_ context.Context, start time.Time, values []float64) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+
+	err := (&chart.CalendarHeatmap{
+		Theme:  chart.DarkTheme,
+		Start:  start,
+		Values: values,
+		Color:  discordutil.ColorSecondary,
+	}).Render(buffer)
+
+	return buffer, err
+}
+
+func (r *LocalChartRenderer) RenderCard(_ context.Context, title, subtitle string, stats []chart.CardStat) (*bytes.Buffer, error) {
+	buffer := &bytes.Buffer{}
+
+	err := (&chart.Card{
+		Width:    600,
+		Theme:    chart.DarkTheme,
+		Accent:   discordutil.ColorPrimary,
+		Title:    title,
+		Subtitle: subtitle,
+		Stats:    stats,
+	}).Render(buffer)
+
+	return buffer, err
+}
+
+var defaultQuickChartURL = url.URL{
+	Scheme: "https",
+	Host:   "quickchart.io",
+	Path:   "/chart",
+}
+
+//go:embed chart_body.json.tmpl
+var barBodyTemplateFile string
+
+//go:embed chart_body_channel_pie.json.tmpl
+var channelPieBodyTemplateFile string
+
+//go:embed chart_body_stacked.json.tmpl
+var stackedBodyTemplateFile string
+
+var barBodyTemplate = template.Must(template.New("body").Parse(barBodyTemplateFile))
+var channelPieBodyTemplate = template.Must(template.New("body").Parse(channelPieBodyTemplateFile))
+var stackedBodyTemplate = template.Must(template.New("body").Parse(stackedBodyTemplateFile))
+
+type barRequestBody struct {
+	Type           string
+	Values         string
+	Labels         string
+	Color          string
+	SecondaryColor string
+	Horizontal     int
+	ShowHorizontal bool
+}
+
+type pieRequestBody struct {
+	Values string
+	Labels string
+}
+
+type stackedRequestBody struct {
+	Labels   string
+	Datasets string
+}
+
+type quickChartDataset struct {
+	Label           string    `json:"label"`
+	Data            []float64 `json:"data"`
+	BackgroundColor string    `json:"backgroundColor"`
+}
+
+// QuickChartRenderer draws charts using the quickchart.io API
+type QuickChartRenderer struct {
+	URL    url.URL
+	Client *http.Client
+}
+
+func NewQuickChartRenderer() *QuickChartRenderer {
+	return &QuickChartRenderer{
+		URL:    defaultQuickChartURL,
+		Client: http.DefaultClient,
+	}
+}
+
+func getQuickChartBarBody(chartType string, xValues []string, yValues []float64, yBar int) (*bytes.Buffer, error) {
+	buffer := bytes.Buffer{}
+
+	values, _ := json.Marshal(yValues)
+	labels, _ := json.Marshal(xValues)
+
+	err := barBodyTemplate.Execute(&buffer, barRequestBody{
+		Type:           chartType,
+		Values:         string(values),
+		Labels:         string(labels),
+		Color:          fmt.Sprintf("\"%s\"", colorAsHex(discordutil.ColorSecondary)),
+		SecondaryColor: fmt.Sprintf("\"%s\"", colorAsHex(discordutil.ColorPrimary)),
+		Horizontal:     yBar,
+		ShowHorizontal: yBar != 0,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	compactBuffer := bytes.Buffer{}
+
+	if err = json.Compact(&compactBuffer, buffer.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return &compactBuffer, nil
+}
+
+func getQuickChartChannelPieBody(labels []string, values []float64) (*bytes.Buffer, error) {
+	buffer := bytes.Buffer{}
+
+	valuesJSON, _ := json.Marshal(values)
+	labelsJSON, _ := json.Marshal(labels)
+
+	err := channelPieBodyTemplate.Execute(&buffer, pieRequestBody{
+		Values: string(valuesJSON),
+		Labels: string(labelsJSON),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	compactBuffer := bytes.Buffer{}
+
+	if err = json.Compact(&compactBuffer, buffer.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return &compactBuffer, nil
+}
+
+func getQuickChartStackedBody(labels []string, series []chart.Series) (*bytes.Buffer, error) {
+	buffer := bytes.Buffer{}
+
+	datasets := make([]quickChartDataset, len(series))
+	for i, s := range series {
+		datasets[i] = quickChartDataset{
+			Label:           s.Name,
+			Data:            s.Values,
+			BackgroundColor: colorAsHex(s.Color),
+		}
+	}
+
+	labelsJSON, _ := json.Marshal(labels)
+	datasetsJSON, _ := json.Marshal(datasets)
+
+	err := stackedBodyTemplate.Execute(&buffer, stackedRequestBody{
+		Labels:   string(labelsJSON),
+		Datasets: string(datasetsJSON),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	compactBuffer := bytes.Buffer{}
+
+	if err = json.Compact(&compactBuffer, buffer.Bytes()); err != nil {
+		return nil, err
+	}
+
+	return &compactBuffer, nil
+}
+
+func (r *QuickChartRenderer) Render(ctx context.Context, chartType string, labels []string, values []float64, goal int) (*bytes.Buffer, error) {
+	if chartType != ChartTypeLine {
+		chartType = ChartTypeBar
+	}
+
+	reqBody, err := getQuickChartBarBody(chartType, labels, values, goal)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.post(ctx, reqBody)
+}
+
+func (r *QuickChartRenderer) RenderStacked(ctx context.Context, labels []string, series []chart.Series) (*bytes.Buffer, error) {
+	reqBody, err := getQuickChartStackedBody(labels, series)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.post(ctx, reqBody)
+}
+
+func (r *QuickChartRenderer) RenderPie(ctx context.Context, labels []string, values []float64) (*bytes.Buffer, error) {
+	reqBody, err := getQuickChartChannelPieBody(labels, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.post(ctx, reqBody)
+}
+
+// RenderHeatmap draws the heatmap locally, quickchart has no calendar charts
+func (r *QuickChartRenderer) RenderHeatmap(ctx context.Context, start time.Time, values []float64) (*bytes.Buffer, error) {
+	return (&LocalChartRenderer{}).RenderHeatmap(ctx, start, values)
+}
+
+// RenderCard draws the card locally, it is not a chart
+func (r *QuickChartRenderer) RenderCard(ctx context.Context, title, subtitle string, stats []chart.CardStat) (*bytes.Buffer, error) {
+	return (&LocalChartRenderer{}).RenderCard(ctx, title, subtitle, stats)
+}
+
+func (r *QuickChartRenderer) post(ctx context.Context, body io.Reader) (*bytes.Buffer, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := r.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to generate chart")
+	}
+
+	buffer := &bytes.Buffer{}
+
+	if _, err = io.Copy(buffer, resp.Body); err != nil {
+		return nil, err
+	}
+
+	return buffer, nil
+}
@@ -55,6 +55,40 @@ var ConfigCommandData = &discordgo.ApplicationCommand{
 			Required:     false,
 			Autocomplete: false,
 		},
+		{
+			Name:        "goal-reminders",
+			Description: "Enable or disable goal reminder DMs",
+			Type:        discordgo.ApplicationCommandOptionBoolean,
+			Required:    false,
+		},
+		{
+			Name:        "streak-freezes",
+			Description: "Set how many missed days a month do not break your streak",
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			MaxValue:    users.MaxStreakFreezes,
+			Required:    false,
+		},
+		{
+			Name:        "privacy",
+			Description: "Set who can see your activity",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "Public",
+					Value: string(users.PrivacyPublic),
+				},
+				{
+					Name:  "Guild only (totals only)",
+					Value: string(users.PrivacyGuild),
+				},
+				{
+					Name:  "Private",
+					Value: string(users.PrivacyPrivate),
+				},
+			},
+		},
 	},
 }
 
@@ -167,6 +201,77 @@ func (c *ConfigCommand) Handle(ctx *bot.InteractionContext) error {
 		}
 
 		embedBuilder.SetDescription("Your daily goal has been updated.")
+	case "goal-reminders":
+		enabled, err := discordutil.GetRequiredBoolOption(options, "goal-reminders")
+
+		if err != nil {
+			return err
+		}
+
+		err = c.userRepository.SetGoalReminders(ctx.Context(), discordutil.GetInteractionUser(i).ID, enabled)
+
+		if err != nil {
+			return err
+		}
+
+		if enabled {
+			embedBuilder.SetDescription("Goal reminders have been enabled.")
+		} else {
+			embedBuilder.SetDescription("Goal reminders have been disabled.")
+		}
+	case "streak-freezes":
+		freezes, err := discordutil.GetRequiredIntOption(options, "streak-freezes")
+
+		if err != nil {
+			return err
+		}
+
+		if freezes < 0 || freezes > users.MaxStreakFreezes {
+			embedBuilder.SetDescription(fmt.Sprintf("Streak freezes must be between 0 and %d.", users.MaxStreakFreezes))
+
+			return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embedBuilder.MessageEmbed},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			})
+		}
+
+		err = c.userRepository.SetStreakFreezes(ctx.Context(), discordutil.GetInteractionUser(i).ID, int(freezes))
+
+		if err != nil {
+			return err
+		}
+
+		embedBuilder.SetDescription(fmt.Sprintf("Up to %d missed days a month will no longer break your streak.", freezes))
+	case "privacy":
+		privacy, err := discordutil.GetRequiredStringOption(options, "privacy")
+
+		if err != nil {
+			return err
+		}
+
+		if !users.Privacy(privacy).IsValid() {
+			embedBuilder.SetDescription("Invalid privacy setting.")
+
+			return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embedBuilder.MessageEmbed},
+				Flags:  discordgo.MessageFlagsEphemeral,
+			})
+		}
+
+		err = c.userRepository.SetPrivacy(ctx.Context(), discordutil.GetInteractionUser(i).ID, users.Privacy(privacy))
+
+		if err != nil {
+			return err
+		}
+
+		switch users.Privacy(privacy) {
+		case users.PrivacyGuild:
+			embedBuilder.SetDescription("Only members of your guilds can now see the totals of your activity.")
+		case users.PrivacyPrivate:
+			embedBuilder.SetDescription("Your activity is now private and you will not appear on leaderboards.")
+		default:
+			embedBuilder.SetDescription("Your activity is now public.")
+		}
 	default:
 		return fmt.Errorf("unexpected option: %s", options[0].Name)
 	}
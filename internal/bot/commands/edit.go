@@ -0,0 +1,327 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+var EditCommandData = &discordgo.ApplicationCommand{
+	Name:        "edit",
+	Description: "Edit an activity you logged",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			Name:        "id",
+			Description: "The ID of the activity to edit",
+			Required:    true,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "name",
+			Description: "New title/name of the activity",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			Name:        "duration",
+			Description: "New duration of the activity (mins)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "date",
+			Description: "New date of the activity (YYYY-MM-DD HH:MM:SS)",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionString,
+			Name:        "media-type",
+			Description: "New type of media of the activity",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "Anime",
+					Value: activities.ActivityMediaTypeAnime,
+				},
+				{
+					Name:  "Manga",
+					Value: activities.ActivityMediaTypeManga,
+				},
+				{
+					Name:  "Book",
+					Value: activities.ActivityMediaTypeBook,
+				},
+				{
+					Name:  "Video",
+					Value: activities.ActivityMediaTypeVideo,
+				},
+				{
+					Name:  "Visual Novel",
+					Value: activities.ActivityMediaTypeVisualNovel,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			Name:        "characters",
+			Description: "New number of characters read",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			Name:        "pages",
+			Description: "New number of pages read",
+			Required:    false,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			MinValue:    ref.New(0.0),
+			Name:        "episodes",
+			Description: "New number of episodes watched",
+			Required:    false,
+		},
+	},
+}
+
+// meta fields that can be changed with /edit, in display order
+var editableMetaFields = []struct {
+	key   string
+	label string
+}{
+	{"characters", "Characters Read"},
+	{"pages", "Pages Read"},
+	{"episodes", "Episodes Watched"},
+}
+
+type EditCommand struct {
+	r   *activities.ActivityRepository
+	gs  *goals.GoalService
+	ggs *goals.GuildGoalService
+	ts  *users.UserTimeService
+	ss  *users.StreakService
+	mrs *MilestoneRoleSyncer
+}
+
+func NewEditCommand(
+	r *activities.ActivityRepository,
+	gs *goals.GoalService,
+	ggs *goals.GuildGoalService,
+	ts *users.UserTimeService,
+	ss *users.StreakService,
+	mrs *MilestoneRoleSyncer,
+) *EditCommand {
+	return &EditCommand{r: r, gs: gs, ggs: ggs, ts: ts, ss: ss, mrs: mrs}
+}
+
+func (c *EditCommand) Handle(ctx *bot.InteractionContext) error {
+	if err := ctx.DeferResponse(); err != nil {
+		return err
+	}
+
+	var args struct {
+		ID         uint64  `discordopt:"id"`
+		Name       *string `discordopt:"name"`
+		Duration   *uint   `discordopt:"duration"`
+		Date       *string `discordopt:"date"`
+		MediaType  *string `discordopt:"media-type"`
+		Characters *uint   `discordopt:"characters"`
+		Pages      *uint   `discordopt:"pages"`
+		Episodes   *uint   `discordopt:"episodes"`
+	}
+
+	if err := discordutil.UnmarshalOptions(ctx.Options(), &args); err != nil {
+		return err
+	}
+
+	userID := ctx.User().ID
+	guildID := ctx.Interaction().GuildID
+
+	activity, err := c.r.GetByID(ctx.Context(), args.ID, guildID)
+
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && activity.UserID != userID) {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: "Activity not found.",
+		}, false)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	location, err := c.ts.GetTimeLocation(ctx.Context(), userID, guildID)
+	if err != nil {
+		return err
+	}
+
+	// the date is read in the user's timezone, but without the location attached
+	activity.Date = time.Date(
+		activity.Date.Year(),
+		activity.Date.Month(),
+		activity.Date.Day(),
+		activity.Date.Hour(),
+		activity.Date.Minute(),
+		activity.Date.Second(),
+		activity.Date.Nanosecond(),
+		location,
+	)
+
+	before := *activity
+	if meta, ok := activity.Meta.(map[string]interface{}); ok {
+		before.Meta = maps.Clone(meta)
+	} else {
+		activity.Meta = make(map[string]interface{})
+	}
+
+	if args.Name != nil {
+		activity.Name = *args.Name
+	}
+
+	if args.Duration != nil {
+		activity.Duration = time.Duration(*args.Duration) * time.Minute
+	}
+
+	if args.MediaType != nil {
+		activity.MediaType = args.MediaType
+	}
+
+	if args.Date != nil {
+		activity.Date, err = time.ParseInLocation(time.DateTime, *args.Date, location)
+		if err != nil {
+			_, err = ctx.Followup(&discordgo.WebhookParams{
+				Content: "Invalid date provided.",
+			}, false)
+			return err
+		}
+	}
+
+	if args.Characters != nil {
+		activity.SetMeta("characters", *args.Characters)
+	}
+
+	if args.Pages != nil {
+		activity.SetMeta("pages", *args.Pages)
+	}
+
+	if args.Episodes != nil {
+		activity.SetMeta("episodes", *args.Episodes)
+	}
+
+	if err = activities.ValidateExternalActivity(activity); err != nil {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("Unable to edit activity: %s", err),
+		}, false)
+		return err
+	}
+
+	// keep the recorded reading speed consistent with the new values
+	if _, ok := activity.GetMeta("speed"); ok && activity.Duration > 0 {
+		if characters, ok := activity.GetMetaFloat("characters"); ok {
+			activity.SetMeta("speed", characters/activity.Duration.Minutes())
+		} else if pages, ok := activity.GetMetaFloat("pages"); ok {
+			activity.SetMeta("speed", pages/activity.Duration.Minutes())
+		}
+	}
+
+	if err = c.r.Update(ctx.Context(), activity); err != nil {
+		return err
+	}
+
+	completedGoals, err := c.gs.CheckEdited(ctx.Context(), &before, activity)
+	if err != nil {
+		return err
+	}
+
+	if activity.GuildID != nil {
+		if _, err = c.ggs.Recalculate(ctx.Context(), *activity.GuildID); err != nil {
+			return err
+		}
+
+		// the user's total hours may have gone up or down past a milestone
+		if activity.Duration != before.Duration {
+			if _, err = c.mrs.Sync(ctx.Context(), ctx.Session(), *activity.GuildID, activity.UserID); err != nil {
+				return err
+			}
+		}
+	}
+
+	// the date or duration may have moved the activity in or out of a day of the streak
+	if _, err = c.ss.Recalculate(ctx.Context(), activity.UserID, ctx.Interaction().GuildID); err != nil {
+		return err
+	}
+
+	embeds := []*discordgo.MessageEmbed{newActivityEditEmbed(&before, activity).MessageEmbed}
+
+	if len(completedGoals) > 0 {
+		embeds = append(embeds, newGoalsCompletedEmbed(activity, completedGoals).MessageEmbed)
+	}
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: embeds,
+	}, false)
+
+	return err
+}
+
+func newActivityEditEmbed(before, after *activities.Activity) *discordutil.EmbedBuilder {
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Activity updated!").
+		SetFooter(fmt.Sprintf("ID: %d", after.ID), "").
+		SetTimestamp(time.Now()).
+		SetColor(discordutil.ColorSuccess)
+
+	addBeforeAfterField := func(name, beforeValue, afterValue string) {
+		if beforeValue == afterValue {
+			embed.AddField(name, afterValue, false)
+		} else {
+			embed.AddField(name, fmt.Sprintf("~~%s~~ → **%s**", beforeValue, afterValue), false)
+		}
+	}
+
+	mediaTypeString := func(a *activities.Activity) string {
+		if a.MediaType == nil {
+			return "None"
+		}
+		return *a.MediaType
+	}
+
+	addBeforeAfterField("Title", before.Name, after.Name)
+	addBeforeAfterField("Media Type", mediaTypeString(before), mediaTypeString(after))
+	addBeforeAfterField("Duration", before.Duration.String(), after.Duration.String())
+	addBeforeAfterField(
+		"Date",
+		fmt.Sprintf("<t:%d>", before.Date.Unix()),
+		fmt.Sprintf("<t:%d>", after.Date.Unix()),
+	)
+
+	for _, field := range editableMetaFields {
+		beforeValue, beforeOk := before.GetMetaFloat(field.key)
+		afterValue, afterOk := after.GetMetaFloat(field.key)
+
+		if !beforeOk && !afterOk {
+			continue
+		}
+
+		beforeString := "None"
+		if beforeOk {
+			beforeString = fmt.Sprintf("%.0f", beforeValue)
+		}
+
+		addBeforeAfterField(field.label, beforeString, fmt.Sprintf("%.0f", afterValue))
+	}
+
+	return embed
+}
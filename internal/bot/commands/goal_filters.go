@@ -0,0 +1,267 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/mediadata"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+)
+
+// options shared by personal and guild goals
+var goalFilterOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "activity-type",
+		Description: "The type of activity to track.",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Listening",
+				Value: activities.ActivityImmersionTypeListening,
+			},
+			{
+				Name:  "Reading",
+				Value: activities.ActivityImmersionTypeReading,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "media-type",
+		Description: "The type of media to track.",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Visual Novel",
+				Value: activities.ActivityMediaTypeVisualNovel,
+			},
+			{
+				Name:  "Book",
+				Value: activities.ActivityMediaTypeBook,
+			},
+			{
+				Name:  "Manga",
+				Value: activities.ActivityMediaTypeManga,
+			},
+			{
+				Name:  "Anime",
+				Value: activities.ActivityMediaTypeAnime,
+			},
+			{
+				Name:  "Video",
+				Value: activities.ActivityMediaTypeVideo,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "unit",
+		Description: "What the goal is measured in (default duration).",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Duration",
+				Value: goals.GoalUnitDuration,
+			},
+			{
+				Name:  "Characters",
+				Value: goals.GoalUnitCharacters,
+			},
+			{
+				Name:  "Pages",
+				Value: goals.GoalUnitPages,
+			},
+			{
+				Name:  "Episodes",
+				Value: goals.GoalUnitEpisodes,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "youtube-channels",
+		Description: "The YouTube channels to track (comma separated, e.g. @HakuiKoyori,@ui_shig,@MinatoAqua).",
+		Required:    false,
+	},
+	{
+		Type:         discordgo.ApplicationCommandOptionString,
+		Name:         "title",
+		Description:  "Only track a specific anime or visual novel.",
+		Required:     false,
+		Autocomplete: true,
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "tags",
+		Description: "Only track anime with any of these AniDB tags (comma separated, e.g. slice of life,comedy).",
+		Required:    false,
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "name-pattern",
+		Description: "Only track activities whose name contains this text (* matches anything).",
+		Required:    false,
+	},
+}
+
+var errInvalidGoalTitle = errors.New("title must be selected from the autocomplete list")
+
+// prefixes of the values of the title option, selecting
+// which meta field of an activity the ID is matched against
+const (
+	goalTitlePrefixAnidb = "anidb:"
+	goalTitlePrefixVndb  = "vndb:"
+)
+
+// setGoalFilters sets the goal's filters and unit from goalFilterOptions,
+// converting the target to the goal's unit
+func setGoalFilters(goal *goals.Goal, options []*discordgo.ApplicationCommandInteractionDataOption, target int64) error {
+	goal.ActivityType = discordutil.GetStringOption(options, "activity-type")
+	goal.MediaType = discordutil.GetStringOption(options, "media-type")
+
+	if ytChannels := discordutil.GetStringOption(options, "youtube-channels"); ytChannels != nil {
+		goal.YoutubeChannels = strings.Split(*ytChannels, ",")
+	}
+
+	goal.Unit = discordutil.GetStringOptionOrDefault(options, "unit", goals.GoalUnitDuration)
+	goal.Target = target
+
+	if goal.Unit == goals.GoalUnitDuration {
+		goal.Target = int64(time.Duration(target) * time.Minute)
+	}
+
+	return setGoalWorkFilters(goal, options)
+}
+
+// setGoalWorkFilters sets the goal's title, tags and name pattern filters
+// from the options that were given, leaving the others unchanged
+func setGoalWorkFilters(goal *goals.Goal, options []*discordgo.ApplicationCommandInteractionDataOption) error {
+	if title := discordutil.GetStringOption(options, "title"); title != nil {
+		if id, ok := strings.CutPrefix(*title, goalTitlePrefixAnidb); ok && id != "" {
+			goal.AnidbIDs = []string{id}
+			goal.VndbIDs = nil
+		} else if id, ok := strings.CutPrefix(*title, goalTitlePrefixVndb); ok && id != "" {
+			goal.AnidbIDs = nil
+			goal.VndbIDs = []string{id}
+		} else {
+			return errInvalidGoalTitle
+		}
+	}
+
+	if tags := discordutil.GetStringOption(options, "tags"); tags != nil {
+		goal.Tags = nil
+		for _, tag := range strings.Split(*tags, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				goal.Tags = append(goal.Tags, tag)
+			}
+		}
+	}
+
+	if pattern := discordutil.GetStringOption(options, "name-pattern"); pattern != nil {
+		goal.NamePattern = pattern
+	}
+
+	return nil
+}
+
+func respondInvalidGoalTitle(cmd *bot.InteractionContext) error {
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: "Invalid title provided. Please pick a title from the list.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	)
+}
+
+// respondGoalAutocomplete responds to autocomplete interactions of
+// commands using goalFilterOptions and a cron option
+func respondGoalAutocomplete(cmd *bot.InteractionContext, ms *mediadata.MediaSearcher) error {
+	if len(cmd.Options()) == 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	options := cmd.Options()[0].Options
+	focusedOption := discordutil.GetFocusedOption(options)
+
+	if focusedOption == nil || focusedOption.Name != "title" {
+		return respondCronAutocomplete(cmd)
+	}
+
+	mediaType := discordutil.GetStringOptionOrDefault(options, "media-type", "")
+	choices, err := createGoalTitleAutocompleteResult(cmd.ResponseContext(), ms, mediaType, focusedOption.StringValue())
+	if err != nil {
+		return err
+	}
+
+	return cmd.Respond(discordgo.InteractionApplicationCommandAutocompleteResult, &discordgo.InteractionResponseData{
+		Choices: choices,
+	})
+}
+
+func createGoalTitleAutocompleteResult(
+	ctx context.Context,
+	ms *mediadata.MediaSearcher,
+	mediaType, input string,
+) (choices []*discordgo.ApplicationCommandOptionChoice, err error) {
+	animeLimit, vnLimit := 12, 13
+
+	switch mediaType {
+	case activities.ActivityMediaTypeAnime:
+		animeLimit, vnLimit = 25, 0
+	case activities.ActivityMediaTypeVisualNovel:
+		animeLimit, vnLimit = 0, 25
+	case "":
+	default:
+		// only anime and visual novels can be matched by title
+		return
+	}
+
+	choices = make([]*discordgo.ApplicationCommandOptionChoice, 0, 25)
+
+	if animeLimit > 0 {
+		var results []mediadata.Match[mediadata.Anime]
+		if results, err = ms.SearchAnime(ctx, input, animeLimit); err != nil {
+			return
+		}
+
+		for _, result := range results {
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncateLongString(fmt.Sprintf("%s (Anime)", result.Value.PrimaryTitle), 100),
+				Value: goalTitlePrefixAnidb + result.Value.ID,
+			})
+		}
+	}
+
+	if vnLimit > 0 {
+		var results []mediadata.Match[mediadata.VisualNovel]
+		if results, err = ms.SearchVisualNovel(ctx, input, vnLimit); err != nil {
+			return
+		}
+
+		for _, result := range results {
+			title := result.Value.JapaneseTitle
+			switch result.Field {
+			case mediadata.VNSearchFieldEnglishTitle:
+				title = result.Value.EnglishTitle
+			case mediadata.VNSearchFieldRomajiTitle:
+				title = result.Value.RomajiTitle
+			}
+
+			choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:  truncateLongString(fmt.Sprintf("%s (Visual Novel)", title), 100),
+				Value: goalTitlePrefixVndb + result.Value.ID,
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+)
+
+// GoalReminderSender sends users a DM when one of their goals
+// is about to reset without being completed
+type GoalReminderSender struct {
+	goals  *goals.GoalService
+	logger *slog.Logger
+}
+
+func NewGoalReminderSender(goals *goals.GoalService, logger *slog.Logger) *GoalReminderSender {
+	return &GoalReminderSender{goals: goals, logger: logger}
+}
+
+// Send sends all pending reminders, returning the number of reminders sent
+func (r *GoalReminderSender) Send(ctx context.Context, s *discordgo.Session) (sent int, err error) {
+	now := time.Now()
+
+	// goals are otherwise only rolled over when their user uses the bot,
+	// which users who need to be reminded may not have done since the last reset
+	userIDs, err := r.goals.FindUserIDsWithDueGoals(ctx, now)
+	if err != nil {
+		return
+	}
+
+	for _, userID := range userIDs {
+		if _, rollErr := r.goals.CheckAll(ctx, userID); rollErr != nil {
+			r.logger.Error(
+				"Unable to roll over goals",
+				slog.String("user_id", userID),
+				slog.String("err", rollErr.Error()),
+			)
+		}
+	}
+
+	pending, err := r.goals.PendingReminders(ctx, now)
+	if err != nil {
+		return
+	}
+
+	for _, g := range pending {
+		// mark the reminder as sent first so that it is not
+		// sent again if another check runs before this one finishes
+		var marked bool
+		if marked, err = r.goals.MarkReminded(ctx, g.ID, g.DueAt); err != nil {
+			return
+		}
+
+		if !marked {
+			continue
+		}
+
+		if dmErr := sendGoalReminder(s, g); dmErr != nil {
+			// users can have DMs disabled, which should not stop the other reminders
+			r.logger.Warn(
+				"Unable to send goal reminder",
+				slog.Int64("goal_id", g.ID),
+				slog.String("user_id", g.UserID),
+				slog.String("err", dmErr.Error()),
+			)
+			continue
+		}
+
+		sent++
+	}
+
+	return
+}
+
+func sendGoalReminder(s *discordgo.Session, g *goals.Goal) error {
+	channel, err := s.UserChannelCreate(g.UserID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.ChannelMessageSendEmbed(channel.ID, newGoalReminderEmbed(g).MessageEmbed)
+	return err
+}
+
+func newGoalReminderEmbed(g *goals.Goal) *discordutil.EmbedBuilder {
+	return discordutil.NewEmbedBuilder().
+		SetTitle("Goal reminder").
+		SetColor(discordutil.ColorWarning).
+		SetTimestamp(time.Now()).
+		SetFooter(fmt.Sprintf("Goal ID: %d • Disable with /config goal-reminders", g.ID), "").
+		SetDescription(fmt.Sprintf("Your goal **%s** resets <t:%d:R>.", g.Name, g.DueAt.Unix())).
+		AddField("Progress", fmt.Sprintf(
+			"%s / %s **(%.2f%%)**",
+			g.FormatValue(g.Current),
+			g.FormatValue(g.Target),
+			g.Percent(),
+		), false).
+		AddField("Remaining", g.FormatValue(g.Target-g.Current), false)
+}
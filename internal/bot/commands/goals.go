@@ -8,10 +8,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
 	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/mediadata"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
 	"github.com/adhocore/gronx"
 	"github.com/bwmarrin/discordgo"
 	"github.com/jackc/pgx/v5"
@@ -25,7 +26,7 @@ var GoalCommandData = &discordgo.ApplicationCommand{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "create",
 			Description: "Create a new goal.",
-			Options: []*discordgo.ApplicationCommandOption{
+			Options: append([]*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "name",
@@ -35,7 +36,8 @@ var GoalCommandData = &discordgo.ApplicationCommand{
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
 					Name:        "target",
-					Description: "The target duration of the goal.",
+					Description: "The target of the goal (in minutes for duration goals).",
+					MinValue:    ref.New(1.0),
 					Required:    true,
 				},
 				{
@@ -46,66 +48,121 @@ var GoalCommandData = &discordgo.ApplicationCommand{
 					Autocomplete: true,
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "activity-type",
-					Description: "The type of activity to track.",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "reminder",
+					Description: "Send a DM this many minutes before the goal resets if it is not completed.",
+					MinValue:    ref.New(0.0),
 					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{
-							Name:  "Listening",
-							Value: activities.ActivityImmersionTypeListening,
-						},
-						{
-							Name:  "Reading",
-							Value: activities.ActivityImmersionTypeReading,
-						},
-					},
+				},
+			}, goalFilterOptions...),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List your goals.",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "delete",
+			Description: "Delete a goal.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "edit",
+			Description: "Edit a goal, keeping its history.",
+			Options: append([]*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
 				},
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "media-type",
-					Description: "The type of media to track.",
+					Name:        "name",
+					Description: "The new name of the goal.",
 					Required:    false,
-					Choices: []*discordgo.ApplicationCommandOptionChoice{
-						{
-							Name:  "Visual Novel",
-							Value: activities.ActivityMediaTypeVisualNovel,
-						},
-						{
-							Name:  "Book",
-							Value: activities.ActivityMediaTypeBook,
-						},
-						{
-							Name:  "Manga",
-							Value: activities.ActivityMediaTypeManga,
-						},
-						{
-							Name:  "Anime",
-							Value: activities.ActivityMediaTypeAnime,
-						},
-						{
-							Name:  "Video",
-							Value: activities.ActivityMediaTypeVideo,
-						},
-					},
 				},
 				{
-					Type:        discordgo.ApplicationCommandOptionString,
-					Name:        "youtube-channels",
-					Description: "The YouTube channels to track (comma separated, e.g. @HakuiKoyori,@ui_shig,@MinatoAqua).",
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "target",
+					Description: "The new target of the goal (in minutes for duration goals).",
+					MinValue:    ref.New(1.0),
 					Required:    false,
 				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "cron",
+					Description:  "The new cron expression for the goal.",
+					Required:     false,
+					Autocomplete: true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionBoolean,
+					Name:        "clear-filters",
+					Description: "Remove all of the goal's filters.",
+					Required:    false,
+				},
+			}, goalFilterOptions...),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "pause",
+			Description: "Pause a goal, it will not track progress or reset until resumed.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
 			},
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
-			Name:        "list",
-			Description: "List your goals.",
+			Name:        "resume",
+			Description: "Resume a paused goal.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
+			},
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
-			Name:        "delete",
-			Description: "Delete a goal.",
+			Name:        "reminder",
+			Description: "Set when to be reminded about an uncompleted goal.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "minutes",
+					Description: "How many minutes before the goal resets to send a DM (0 to disable).",
+					MinValue:    ref.New(0.0),
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "history",
+			Description: "View the history and streaks of a goal.",
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionInteger,
@@ -118,12 +175,16 @@ var GoalCommandData = &discordgo.ApplicationCommand{
 	},
 }
 
+// number of finished periods shown by /goal history
+const goalHistoryPeriods = 10
+
 type GoalCommand struct {
 	goals *goals.GoalService
+	ms    *mediadata.MediaSearcher
 }
 
-func NewGoalCommand(goals *goals.GoalService) *GoalCommand {
-	return &GoalCommand{goals: goals}
+func NewGoalCommand(goals *goals.GoalService, ms *mediadata.MediaSearcher) *GoalCommand {
+	return &GoalCommand{goals: goals, ms: ms}
 }
 
 func (c *GoalCommand) Handle(cmd *bot.InteractionContext) error {
@@ -144,6 +205,16 @@ func (c *GoalCommand) Handle(cmd *bot.InteractionContext) error {
 		return c.handleList(cmd, subcommand)
 	case "delete":
 		return c.handleDelete(cmd, subcommand)
+	case "history":
+		return c.handleHistory(cmd, subcommand)
+	case "reminder":
+		return c.handleReminder(cmd, subcommand)
+	case "edit":
+		return c.handleEdit(cmd, subcommand)
+	case "pause":
+		return c.handlePause(cmd, subcommand, true)
+	case "resume":
+		return c.handlePause(cmd, subcommand, false)
 	default:
 		return bot.ErrInvalidOptions
 	}
@@ -195,6 +266,278 @@ func (c *GoalCommand) handleDelete(cmd *bot.InteractionContext, subcommand *disc
 	)
 }
 
+func (c *GoalCommand) handleReminder(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	minutes, err := discordutil.GetRequiredIntOption(subcommand.Options, "minutes")
+	if err != nil {
+		return err
+	}
+
+	goal, err := c.goals.Modify(cmd.ResponseContext(), cmd.User().ID, id, func(g *goals.Goal, _ time.Time) error {
+		g.Reminder = time.Duration(minutes) * time.Minute
+		return nil
+	})
+
+	if errors.Is(err, goals.ErrGoalNotFound) {
+		return respondGoalNotFound(cmd, id)
+	} else if err != nil {
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	content := fmt.Sprintf("Reminders disabled for goal **%s**.", goal.Name)
+	if goal.Reminder > 0 {
+		content = fmt.Sprintf("You will be reminded about goal **%s** %s before it resets.", goal.Name, goal.Reminder)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: content,
+		},
+	)
+}
+
+func (c *GoalCommand) handleEdit(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	name := discordutil.GetStringOption(subcommand.Options, "name")
+	target := discordutil.GetIntOption(subcommand.Options, "target")
+	cron := discordutil.GetStringOption(subcommand.Options, "cron")
+	activityType := discordutil.GetStringOption(subcommand.Options, "activity-type")
+	mediaType := discordutil.GetStringOption(subcommand.Options, "media-type")
+	unit := discordutil.GetStringOption(subcommand.Options, "unit")
+	ytChannels := discordutil.GetStringOption(subcommand.Options, "youtube-channels")
+	clearFilters := discordutil.GetBoolOptionOrDefault(subcommand.Options, "clear-filters", false)
+
+	if cron != nil {
+		gron := gronx.New()
+
+		if !gron.IsValid(*cron) {
+			return cmd.Respond(
+				discordgo.InteractionResponseChannelMessageWithSource,
+				&discordgo.InteractionResponseData{
+					Content: "Invalid cron provided. See https://crontab.guru/ for help on creating a valid cron expression.",
+				},
+			)
+		}
+	}
+
+	// the old target would be meaningless in the new unit
+	if unit != nil && target == nil {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "A new target must be provided when changing the unit of a goal.",
+			},
+		)
+	}
+
+	goal, err := c.goals.Modify(cmd.ResponseContext(), cmd.User().ID, id, func(g *goals.Goal, now time.Time) error {
+		if name != nil {
+			g.Name = *name
+		}
+
+		if clearFilters {
+			g.ActivityType = nil
+			g.MediaType = nil
+			g.YoutubeChannels = nil
+			g.AnidbIDs = nil
+			g.VndbIDs = nil
+			g.Tags = nil
+			g.NamePattern = nil
+		}
+
+		if activityType != nil {
+			g.ActivityType = activityType
+		}
+
+		if mediaType != nil {
+			g.MediaType = mediaType
+		}
+
+		if ytChannels != nil {
+			g.YoutubeChannels = strings.Split(*ytChannels, ",")
+		}
+
+		if err := setGoalWorkFilters(g, subcommand.Options); err != nil {
+			return err
+		}
+
+		if unit != nil {
+			g.Unit = *unit
+		}
+
+		if target != nil {
+			g.Target = *target
+
+			if g.Unit == goals.GoalUnitDuration {
+				g.Target = int64(time.Duration(*target) * time.Minute)
+			}
+		}
+
+		if cron != nil && *cron != g.Cron {
+			g.Cron = *cron
+			return g.Reschedule(now)
+		}
+
+		return nil
+	})
+
+	if errors.Is(err, goals.ErrGoalNotFound) {
+		return respondGoalNotFound(cmd, id)
+	} else if errors.Is(err, errInvalidGoalTitle) {
+		return respondInvalidGoalTitle(cmd)
+	} else if err != nil {
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf(
+				"Goal **%s** updated! Progress: %s / %s **(%.2f%%)**",
+				goal.Name,
+				goal.FormatValue(goal.Current),
+				goal.FormatValue(goal.Target),
+				goal.Percent(),
+			),
+		},
+	)
+}
+
+func (c *GoalCommand) handlePause(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption, pause bool) error {
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	goal, err := c.goals.Modify(cmd.ResponseContext(), cmd.User().ID, id, func(g *goals.Goal, now time.Time) error {
+		if pause {
+			g.Pause(now)
+			return nil
+		}
+
+		return g.Resume(now)
+	})
+
+	if errors.Is(err, goals.ErrGoalNotFound) {
+		return respondGoalNotFound(cmd, id)
+	} else if err != nil {
+		return fmt.Errorf("failed to update goal: %w", err)
+	}
+
+	content := fmt.Sprintf("Goal **%s** paused.", goal.Name)
+	if !pause {
+		content = fmt.Sprintf("Goal **%s** resumed, next reset: <t:%d>", goal.Name, goal.DueAt.Unix())
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: content,
+		},
+	)
+}
+
+func respondGoalNotFound(cmd *bot.InteractionContext, id int64) error {
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("No goal found with ID: %d", id),
+		},
+	)
+}
+
+func (c *GoalCommand) handleHistory(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	// roll over due goals first so that their last period is included
+	userGoals, err := c.goals.CheckAll(cmd.ResponseContext(), cmd.User().ID)
+	if err != nil {
+		return fmt.Errorf("failed to find goals: %w", err)
+	}
+
+	var goal *goals.Goal
+	for _, g := range userGoals {
+		if g.ID == id {
+			goal = g
+			break
+		}
+	}
+
+	if goal == nil {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("No goal found with ID: %d", id),
+			},
+		)
+	}
+
+	periods, err := c.goals.FindPeriodsByGoalID(cmd.ResponseContext(), goal.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find goal history: %w", err)
+	}
+
+	streaks := goals.CalculateStreaks(periods)
+
+	// the ongoing period extends the streak once its target is reached
+	if goal.IsCompleted() {
+		streaks.Current++
+		streaks.Longest = max(streaks.Longest, streaks.Current)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("%s (%d)", goal.Name, goal.ID)).
+		SetColor(discordutil.ColorPrimary).
+		SetTimestamp(time.Now()).
+		AddField("Current Streak", fmt.Sprintf("%d", streaks.Current), true).
+		AddField("Longest Streak", fmt.Sprintf("%d", streaks.Longest), true).
+		AddField("Hit Rate", fmt.Sprintf("%.2f%% (%d/%d)", streaks.HitRate(), streaks.Met, streaks.Total), true)
+
+	if len(periods) == 0 {
+		embed.SetDescription("This goal has not finished any periods yet.")
+	} else {
+		var b strings.Builder
+		for i := len(periods) - 1; i >= max(len(periods)-goalHistoryPeriods, 0); i-- {
+			p := periods[i]
+			mark := "❌"
+			if p.Met {
+				mark = "✅"
+			}
+
+			fmt.Fprintf(
+				&b,
+				"%s <t:%d:d> - <t:%d:d>: %s / %s\n",
+				mark,
+				p.PeriodStart.Unix(),
+				p.PeriodEnd.Unix(),
+				goal.FormatValue(p.Achieved),
+				goal.FormatValue(p.Target),
+			)
+		}
+
+		embed.AddField("Recent Periods", b.String(), false)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		},
+	)
+}
+
 func (c *GoalCommand) handleList(cmd *bot.InteractionContext, _ *discordgo.ApplicationCommandInteractionDataOption) error {
 	goals, err := c.goals.CheckAll(cmd.ResponseContext(), cmd.User().ID)
 
@@ -221,19 +564,30 @@ func (c *GoalCommand) handleList(cmd *bot.InteractionContext, _ *discordgo.Appli
 		SetTimestamp(time.Now())
 
 	for _, goal := range goals {
+		title := fmt.Sprintf("%s (%d)", goal.Name, goal.ID)
+
+		if goal.IsPaused() {
+			embed.AddField(title, fmt.Sprintf(
+				"Progress: %s / %s **(%.2f%%)**\nPaused: <t:%d>",
+				goal.FormatValue(goal.Current),
+				goal.FormatValue(goal.Target),
+				goal.Percent(),
+				goal.PausedAt.Unix(),
+			), false)
+			continue
+		}
+
 		nextDueDate, err := c.goals.NextCron(cmd.Context(), goal)
 
 		if err != nil {
 			return fmt.Errorf("failed to calculate next due date: %w", err)
 		}
 
-		title := fmt.Sprintf("%s (%d)", goal.Name, goal.ID)
-
 		embed.AddField(title, fmt.Sprintf(
 			"Progress: %s / %s **(%.2f%%)**\nNext Reset: <t:%d>",
-			goal.Current,
-			goal.Target,
-			goal.Current.Seconds()/goal.Target.Seconds()*100,
+			goal.FormatValue(goal.Current),
+			goal.FormatValue(goal.Target),
+			goal.Percent(),
 			nextDueDate.Unix(),
 		), false)
 	}
@@ -348,34 +702,26 @@ func (c *GoalCommand) handleCreate(cmd *bot.InteractionContext, subcommand *disc
 		)
 	}
 
-	activityType := discordutil.GetStringOption(subcommand.Options, "activity-type")
-	mediaType := discordutil.GetStringOption(subcommand.Options, "media-type")
-	ytChannels := discordutil.GetStringOption(subcommand.Options, "youtube-channels")
-
 	goal := &goals.Goal{}
-
-	if activityType != nil {
-		goal.ActivityType = activityType
-	}
-
-	if mediaType != nil {
-		goal.MediaType = mediaType
-	}
-
-	if ytChannels != nil {
-		goal.YoutubeChannels = strings.Split(*ytChannels, ",")
+	if err = setGoalFilters(goal, subcommand.Options, target); err != nil {
+		return respondInvalidGoalTitle(cmd)
 	}
 
 	goal.Name = name
-	goal.Target = time.Duration(target) * time.Minute
 	goal.Cron = cron
 	goal.UserID = cmd.User().ID
+	goal.Reminder = time.Duration(discordutil.GetUintOptionOrDefault(subcommand.Options, "reminder", 0)) * time.Minute
 	goal.DueAt, err = c.goals.NextCron(cmd.ResponseContext(), goal)
 
 	if err != nil {
 		return fmt.Errorf("failed to calculate due date: %w", err)
 	}
 
+	// count activities already logged in the current period
+	if err = c.goals.Calculate(cmd.ResponseContext(), goal); err != nil {
+		return fmt.Errorf("failed to calculate goal progress: %w", err)
+	}
+
 	cmd.Logger.Debug("Creating goal", slog.Any("goal", goal))
 
 	if err := c.goals.Create(cmd.ResponseContext(), goal); err != nil {
@@ -391,6 +737,10 @@ func (c *GoalCommand) handleCreate(cmd *bot.InteractionContext, subcommand *disc
 }
 
 func (c *GoalCommand) handleAutocomplete(cmd *bot.InteractionContext) error {
+	return respondGoalAutocomplete(cmd, c.ms)
+}
+
+func respondCronAutocomplete(cmd *bot.InteractionContext) error {
 	choices := [...]*discordgo.ApplicationCommandOptionChoice{
 		{
 			Name:  "Daily",
@@ -1,12 +1,15 @@
 package commands
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/UTD-JLA/botsu/internal/bot"
 	"github.com/UTD-JLA/botsu/internal/guilds"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
 	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/adhocore/gronx"
 	"github.com/bwmarrin/discordgo"
 )
 
@@ -23,9 +26,62 @@ var GuildConfigCommandData = &discordgo.ApplicationCommand{
 			Required:     false,
 			Autocomplete: true,
 		},
+		{
+			Name:        "week-start",
+			Description: "Set the first day of the week of weekly leaderboards",
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "Sunday",
+					Value: int(time.Sunday),
+				},
+				{
+					Name:  "Monday",
+					Value: int(time.Monday),
+				},
+				{
+					Name:  "Tuesday",
+					Value: int(time.Tuesday),
+				},
+				{
+					Name:  "Wednesday",
+					Value: int(time.Wednesday),
+				},
+				{
+					Name:  "Thursday",
+					Value: int(time.Thursday),
+				},
+				{
+					Name:  "Friday",
+					Value: int(time.Friday),
+				},
+				{
+					Name:  "Saturday",
+					Value: int(time.Saturday),
+				},
+			},
+		},
+		{
+			Name:         "leaderboard-cron",
+			Description:  "Post the leaderboard of each period of this cron, or \"off\" to stop",
+			Type:         discordgo.ApplicationCommandOptionString,
+			Required:     false,
+			Autocomplete: true,
+		},
+		{
+			Name:         "leaderboard-channel",
+			Description:  "Set the channel scheduled leaderboards are posted in",
+			Type:         discordgo.ApplicationCommandOptionChannel,
+			Required:     false,
+			ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+		},
 	},
 }
 
+// leaderboardScheduleOff turns off scheduled leaderboards when given as the cron
+const leaderboardScheduleOff = "off"
+
 type GuildConfigCommand struct {
 	r *guilds.GuildRepository
 }
@@ -42,6 +98,10 @@ func (c *GuildConfigCommand) Handle(ctx *bot.InteractionContext) error {
 	i := ctx.Interaction()
 	options := ctx.Options()
 
+	if isLeaderboardScheduleOptions(options) {
+		return c.handleLeaderboardSchedule(ctx)
+	}
+
 	if len(options) != 1 {
 		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
 			Content: "You must provide one option!",
@@ -71,10 +131,120 @@ func (c *GuildConfigCommand) Handle(ctx *bot.InteractionContext) error {
 		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
 			Content: "Timezone set!",
 		})
+	case "week-start":
+		weekStart, err := discordutil.GetRequiredIntOption(options, "week-start")
+
+		if err != nil {
+			return err
+		}
+
+		if weekStart < int64(time.Sunday) || weekStart > int64(time.Saturday) {
+			return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+				Content: "Invalid day of the week",
+			})
+		}
+
+		// the week start is only updated for guilds that have a row
+		if _, err = c.r.FindOrCreate(ctx.Context(), i.GuildID); err != nil {
+			return err
+		}
+
+		err = c.r.SetGuildWeekStart(ctx.Context(), i.GuildID, time.Weekday(weekStart))
+		if err != nil {
+			return err
+		}
+
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Weeks now start on %s!", time.Weekday(weekStart)),
+		})
 	}
 	return nil
 }
 
+// isLeaderboardScheduleOptions reports whether only the leaderboard schedule
+// options were given, which unlike the others may be set together
+func isLeaderboardScheduleOptions(options []*discordgo.ApplicationCommandInteractionDataOption) bool {
+	if len(options) == 0 {
+		return false
+	}
+
+	for _, option := range options {
+		if option.Name != "leaderboard-cron" && option.Name != "leaderboard-channel" {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *GuildConfigCommand) handleLeaderboardSchedule(ctx *bot.InteractionContext) error {
+	i := ctx.Interaction()
+	options := ctx.Options()
+
+	guild, err := c.r.FindOrCreate(ctx.ResponseContext(), i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	cron := discordutil.GetStringOptionOrDefault(options, "leaderboard-cron", "")
+
+	if cron == leaderboardScheduleOff {
+		if err = c.r.SetLeaderboardSchedule(ctx.ResponseContext(), i.GuildID, nil, nil, nil); err != nil {
+			return err
+		}
+
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "Leaderboards will no longer be posted.",
+		})
+	}
+
+	gron := gronx.New()
+
+	if cron == "" {
+		if guild.LeaderboardCron == nil {
+			return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+				Content: "You must also provide a leaderboard cron!",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			})
+		}
+
+		cron = *guild.LeaderboardCron
+	} else if !gron.IsValid(cron) {
+		return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "Invalid cron provided. See https://crontab.guru/ for help on creating a valid cron expression.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	// default to the channel already in use, or else the one the command was used in
+	channelID := i.ChannelID
+	if channel := discordutil.GetChannelOption(options, "leaderboard-channel", ctx.Session()); channel != nil {
+		channelID = channel.ID
+	} else if guild.LeaderboardChannelID != nil {
+		channelID = *guild.LeaderboardChannelID
+	}
+
+	location := time.UTC
+	if guild.Timezone != nil {
+		if location, err = time.LoadLocation(*guild.Timezone); err != nil {
+			return err
+		}
+	}
+
+	dueAt, err := gronx.NextTickAfter(cron, time.Now().In(location), false)
+	if err != nil {
+		return err
+	}
+
+	if err = c.r.SetLeaderboardSchedule(ctx.ResponseContext(), i.GuildID, &channelID, &cron, &dueAt); err != nil {
+		return err
+	}
+
+	return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Content: fmt.Sprintf("Leaderboards will be posted in <#%s>, the next one <t:%d:R>.", channelID, dueAt.Unix()),
+	})
+}
+
 func (c *GuildConfigCommand) handleAutocomplete(ctx *bot.InteractionContext) error {
 	focuedOption := discordutil.GetFocusedOption(ctx.Options())
 
@@ -83,6 +253,8 @@ func (c *GuildConfigCommand) handleAutocomplete(ctx *bot.InteractionContext) err
 	}
 
 	switch focuedOption.Name {
+	case "leaderboard-cron":
+		return respondCronAutocomplete(ctx)
 	case "timezone":
 		const maxResults = 25
 		timezone := focuedOption.StringValue()
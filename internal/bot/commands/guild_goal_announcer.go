@@ -0,0 +1,85 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+)
+
+// GuildGoalAnnouncer announces completed guild goals in their configured channel
+type GuildGoalAnnouncer struct {
+	goals  *goals.GuildGoalService
+	logger *slog.Logger
+}
+
+func NewGuildGoalAnnouncer(goals *goals.GuildGoalService, logger *slog.Logger) *GuildGoalAnnouncer {
+	return &GuildGoalAnnouncer{goals: goals, logger: logger}
+}
+
+// Send announces all completed goals that have not been announced yet,
+// returning the number of announcements sent
+func (a *GuildGoalAnnouncer) Send(ctx context.Context, s *discordgo.Session) (sent int, err error) {
+	pending, err := a.goals.FindPendingAnnouncements(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, g := range pending {
+		// see GoalReminderSender.Send
+		var marked bool
+		if marked, err = a.goals.MarkAnnounced(ctx, g.ID, g.DueAt); err != nil {
+			return
+		}
+
+		if !marked {
+			continue
+		}
+
+		var contributions []*goals.GuildGoalContribution
+		if contributions, err = a.goals.FindTopContributions(ctx, g.ID, g.DueAt, guildGoalTopContributors); err != nil {
+			return
+		}
+
+		_, sendErr := s.ChannelMessageSendComplex(g.ChannelID, &discordgo.MessageSend{
+			Embeds:          []*discordgo.MessageEmbed{newGuildGoalCompletedEmbed(g, contributions).MessageEmbed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+
+		if sendErr != nil {
+			// the channel may have been deleted or made inaccessible
+			a.logger.Warn(
+				"Unable to announce guild goal",
+				slog.Int64("goal_id", g.ID),
+				slog.String("channel_id", g.ChannelID),
+				slog.String("err", sendErr.Error()),
+			)
+			continue
+		}
+
+		sent++
+	}
+
+	return
+}
+
+func newGuildGoalCompletedEmbed(g *goals.GuildGoal, contributions []*goals.GuildGoalContribution) *discordutil.EmbedBuilder {
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Server goal completed!").
+		SetColor(discordutil.ColorSuccess).
+		SetTimestamp(time.Now()).
+		SetFooter(fmt.Sprintf("Goal ID: %d", g.ID), "").
+		SetDescription(fmt.Sprintf("The server has completed the goal **%s**!", g.Name)).
+		AddField("Target", g.FormatValue(g.Target), true).
+		AddField("Completed", g.FormatValue(g.Current), true)
+
+	if len(contributions) > 0 {
+		embed.AddField("Top Contributors", formatGuildGoalContributions(g, contributions), false)
+	}
+
+	return embed
+}
@@ -0,0 +1,376 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/guilds"
+	"github.com/UTD-JLA/botsu/internal/mediadata"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/adhocore/gronx"
+	"github.com/bwmarrin/discordgo"
+)
+
+var GuildGoalCommandData = &discordgo.ApplicationCommand{
+	Name:         "guild-goal",
+	Description:  "Manage goals shared by the whole server.",
+	DMPermission: ref.New(false),
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "create",
+			Description: "Create a new server goal (requires Manage Server).",
+			Options: append([]*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "The name of the goal.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "target",
+					Description: "The target of the goal (in minutes for duration goals).",
+					MinValue:    ref.New(1.0),
+					Required:    true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionString,
+					Name:         "cron",
+					Description:  "The cron expression for the goal.",
+					Required:     true,
+					Autocomplete: true,
+				},
+				{
+					Type:         discordgo.ApplicationCommandOptionChannel,
+					Name:         "channel",
+					Description:  "The channel to announce the goal's completion in (default current channel).",
+					ChannelTypes: []discordgo.ChannelType{discordgo.ChannelTypeGuildText},
+					Required:     false,
+				},
+			}, goalFilterOptions...),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the server's goals.",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "contributors",
+			Description: "View the top contributors to a server goal.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "delete",
+			Description: "Delete a server goal (requires Manage Server).",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "id",
+					Description: "The ID of the goal.",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+// number of members shown in contribution lists
+const guildGoalTopContributors = 10
+
+type GuildGoalCommand struct {
+	goals  *goals.GuildGoalService
+	guilds *guilds.GuildRepository
+	ms     *mediadata.MediaSearcher
+}
+
+func NewGuildGoalCommand(goals *goals.GuildGoalService, guilds *guilds.GuildRepository, ms *mediadata.MediaSearcher) *GuildGoalCommand {
+	return &GuildGoalCommand{goals: goals, guilds: guilds, ms: ms}
+}
+
+func (c *GuildGoalCommand) Handle(cmd *bot.InteractionContext) error {
+	if cmd.IsAutocomplete() {
+		return respondGoalAutocomplete(cmd, c.ms)
+	}
+
+	if len(cmd.Options()) == 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	subcommand := cmd.Options()[0]
+
+	switch subcommand.Name {
+	case "create":
+		return c.handleCreate(cmd, subcommand)
+	case "list":
+		return c.handleList(cmd)
+	case "contributors":
+		return c.handleContributors(cmd, subcommand)
+	case "delete":
+		return c.handleDelete(cmd, subcommand)
+	default:
+		return bot.ErrInvalidOptions
+	}
+}
+
+func canManageGuild(cmd *bot.InteractionContext) bool {
+	member := cmd.Interaction().Member
+	return member != nil && member.Permissions&discordgo.PermissionManageServer != 0
+}
+
+func respondMissingPermission(cmd *bot.InteractionContext) error {
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: "You need the Manage Server permission to do this.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	)
+}
+
+func (c *GuildGoalCommand) findGoal(cmd *bot.InteractionContext, id int64) (*goals.GuildGoal, error) {
+	guildGoals, err := c.goals.CheckAll(cmd.ResponseContext(), cmd.Interaction().GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find goals: %w", err)
+	}
+
+	for _, g := range guildGoals {
+		if g.ID == id {
+			return g, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *GuildGoalCommand) handleCreate(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	name, err := discordutil.GetRequiredStringOption(subcommand.Options, "name")
+	if err != nil {
+		return err
+	}
+
+	target, err := discordutil.GetRequiredIntOption(subcommand.Options, "target")
+	if err != nil {
+		return err
+	}
+
+	cron, err := discordutil.GetRequiredStringOption(subcommand.Options, "cron")
+	if err != nil {
+		return err
+	}
+
+	gron := gronx.New()
+
+	if !gron.IsValid(cron) {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "Invalid cron provided. See https://crontab.guru/ for help on creating a valid cron expression.",
+			},
+		)
+	}
+
+	goal := &goals.GuildGoal{
+		GuildID:   cmd.Interaction().GuildID,
+		ChannelID: cmd.Interaction().ChannelID,
+		CreatedBy: cmd.User().ID,
+	}
+
+	if channel := discordutil.GetChannelOption(subcommand.Options, "channel", cmd.Session()); channel != nil {
+		goal.ChannelID = channel.ID
+	}
+
+	if err = setGoalFilters(&goal.Goal, subcommand.Options, target); err != nil {
+		return respondInvalidGoalTitle(cmd)
+	}
+
+	goal.Name = name
+	goal.Cron = cron
+	goal.DueAt, err = c.goals.NextCron(cmd.ResponseContext(), goal)
+
+	if err != nil {
+		return fmt.Errorf("failed to calculate due date: %w", err)
+	}
+
+	cmd.Logger.Debug("Creating guild goal", slog.Any("goal", goal))
+
+	// guilds are otherwise only created once someone logs an activity in them
+	if _, err = c.guilds.FindOrCreate(cmd.ResponseContext(), goal.GuildID); err != nil {
+		return fmt.Errorf("failed to create guild: %w", err)
+	}
+
+	if err = c.goals.Create(cmd.ResponseContext(), goal); err != nil {
+		return fmt.Errorf("failed to create goal: %w", err)
+	}
+
+	// count activities already logged in the current period
+	if _, err = c.goals.Recalculate(cmd.ResponseContext(), goal.GuildID); err != nil {
+		return fmt.Errorf("failed to calculate goal progress: %w", err)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Server goal **%s** created! Completion will be announced in <#%s>.", goal.Name, goal.ChannelID),
+		},
+	)
+}
+
+func (c *GuildGoalCommand) handleList(cmd *bot.InteractionContext) error {
+	guildGoals, err := c.goals.CheckAll(cmd.ResponseContext(), cmd.Interaction().GuildID)
+	if err != nil {
+		return fmt.Errorf("failed to find goals: %w", err)
+	}
+
+	if len(guildGoals) == 0 {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "This server has no goals! Members with Manage Server can create one with: `/guild-goal create`",
+			},
+		)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Server Goals").
+		SetColor(discordutil.ColorPrimary).
+		SetTimestamp(time.Now())
+
+	for i, goal := range guildGoals {
+		if i == 25 {
+			break
+		}
+
+		embed.AddField(fmt.Sprintf("%s (%d)", goal.Name, goal.ID), fmt.Sprintf(
+			"Progress: %s / %s **(%.2f%%)**\nNext Reset: <t:%d>",
+			goal.FormatValue(goal.Current),
+			goal.FormatValue(goal.Target),
+			goal.Percent(),
+			goal.DueAt.Unix(),
+		), false)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		},
+	)
+}
+
+func (c *GuildGoalCommand) handleContributors(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	goal, err := c.findGoal(cmd, id)
+	if err != nil {
+		return err
+	}
+
+	if goal == nil {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("No goal found with ID: %d", id),
+			},
+		)
+	}
+
+	contributions, err := c.goals.FindTopContributions(cmd.ResponseContext(), goal.ID, goal.DueAt, guildGoalTopContributors)
+	if err != nil {
+		return fmt.Errorf("failed to find contributions: %w", err)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("%s (%d)", goal.Name, goal.ID)).
+		SetColor(discordutil.ColorPrimary).
+		SetTimestamp(time.Now()).
+		AddField("Progress", fmt.Sprintf(
+			"%s / %s **(%.2f%%)**",
+			goal.FormatValue(goal.Current),
+			goal.FormatValue(goal.Target),
+			goal.Percent(),
+		), false)
+
+	if len(contributions) == 0 {
+		embed.SetDescription("Nobody has contributed to this goal yet.")
+	} else {
+		embed.AddField("Top Contributors", formatGuildGoalContributions(goal, contributions), false)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed.MessageEmbed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	)
+}
+
+func (c *GuildGoalCommand) handleDelete(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	id, err := discordutil.GetRequiredIntOption(subcommand.Options, "id")
+	if err != nil {
+		return err
+	}
+
+	goal, err := c.findGoal(cmd, id)
+	if err != nil {
+		return err
+	}
+
+	if goal == nil {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: fmt.Sprintf("No goal found with ID: %d", id),
+			},
+		)
+	}
+
+	cmd.Logger.Debug("Deleting guild goal", slog.Int64("goal_id", id))
+
+	if err = c.goals.DeleteByID(cmd.ResponseContext(), id); err != nil {
+		return err
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("Server goal **%s** deleted.", goal.Name),
+		},
+	)
+}
+
+func formatGuildGoalContributions(goal *goals.GuildGoal, contributions []*goals.GuildGoalContribution) string {
+	var b strings.Builder
+
+	for i, contribution := range contributions {
+		fmt.Fprintf(&b, "%d. <@%s>: %s\n", i+1, contribution.UserID, goal.FormatValue(contribution.Amount))
+	}
+
+	return b.String()
+}
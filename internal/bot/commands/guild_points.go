@@ -0,0 +1,272 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/guilds"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/bwmarrin/discordgo"
+)
+
+var guildPointsRuleOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "unit",
+		Description: "What the points are awarded for.",
+		Required:    true,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Minutes",
+				Value: activities.PointUnitMinutes,
+			},
+			{
+				Name:  "Characters",
+				Value: activities.MetricCharacters,
+			},
+			{
+				Name:  "Pages",
+				Value: activities.MetricPages,
+			},
+			{
+				Name:  "Episodes",
+				Value: activities.MetricEpisodes,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "media-type",
+		Description: "The type of media the rule is for (default all types without rules of their own).",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Visual Novel",
+				Value: activities.ActivityMediaTypeVisualNovel,
+			},
+			{
+				Name:  "Book",
+				Value: activities.ActivityMediaTypeBook,
+			},
+			{
+				Name:  "Manga",
+				Value: activities.ActivityMediaTypeManga,
+			},
+			{
+				Name:  "Anime",
+				Value: activities.ActivityMediaTypeAnime,
+			},
+			{
+				Name:  "Video",
+				Value: activities.ActivityMediaTypeVideo,
+			},
+		},
+	},
+}
+
+var GuildPointsCommandData = &discordgo.ApplicationCommand{
+	Name:         "guild-points",
+	Description:  "Manage how the server awards points for activities.",
+	DMPermission: ref.New(false),
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Award points for every amount of a unit (requires Manage Server).",
+			Options: append([]*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "points",
+					Description: "The number of points awarded.",
+					MinValue:    ref.New(0.0),
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionNumber,
+					Name:        "per",
+					Description: "The amount of the unit the points are awarded for (default 1).",
+					MinValue:    ref.New(0.01),
+					Required:    false,
+				},
+			}, guildPointsRuleOptions...),
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Stop awarding points for a unit (requires Manage Server).",
+			Options:     guildPointsRuleOptions,
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List how the server awards points.",
+		},
+	},
+}
+
+type GuildPointsCommand struct {
+	rules  *activities.PointRuleRepository
+	guilds *guilds.GuildRepository
+}
+
+func NewGuildPointsCommand(rules *activities.PointRuleRepository, guilds *guilds.GuildRepository) *GuildPointsCommand {
+	return &GuildPointsCommand{rules: rules, guilds: guilds}
+}
+
+// formatPointRule describes a rule, for example "1 point per 350 characters"
+func formatPointRule(rule *activities.PointRule) string {
+	points := "points"
+	if rule.Points == 1 {
+		points = "point"
+	}
+
+	if rule.Amount == 1 {
+		return fmt.Sprintf("%g %s per %s", rule.Points, points, strings.TrimSuffix(rule.Unit, "s"))
+	}
+
+	return fmt.Sprintf("%g %s per %g %s", rule.Points, points, rule.Amount, rule.Unit)
+}
+
+// pointRuleMediaTypeName returns the display name of the media type of a rule
+func pointRuleMediaTypeName(mediaType string) string {
+	if mediaType == "" {
+		return "Everything Else"
+	}
+
+	return mediaTypeName(&mediaType)
+}
+
+func (c *GuildPointsCommand) Handle(cmd *bot.InteractionContext) error {
+	if len(cmd.Options()) == 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	subcommand := cmd.Options()[0]
+
+	switch subcommand.Name {
+	case "set":
+		return c.handleSet(cmd, subcommand)
+	case "remove":
+		return c.handleRemove(cmd, subcommand)
+	case "list":
+		return c.handleList(cmd)
+	default:
+		return bot.ErrInvalidOptions
+	}
+}
+
+func (c *GuildPointsCommand) handleSet(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	points, err := discordutil.GetRequiredFloatOption(subcommand.Options, "points")
+	if err != nil {
+		return err
+	}
+
+	unit, err := discordutil.GetRequiredStringOption(subcommand.Options, "unit")
+	if err != nil {
+		return err
+	}
+
+	rule := &activities.PointRule{
+		GuildID:   cmd.Interaction().GuildID,
+		MediaType: discordutil.GetStringOptionOrDefault(subcommand.Options, "media-type", ""),
+		Unit:      unit,
+		Amount:    discordutil.GetFloatOptionOrDefault(subcommand.Options, "per", 1),
+		Points:    points,
+	}
+
+	if rule.Amount <= 0 || rule.Points < 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	// guilds are otherwise only created once someone logs an activity in them
+	if _, err = c.guilds.FindOrCreate(cmd.ResponseContext(), rule.GuildID); err != nil {
+		return err
+	}
+
+	if err = c.rules.Set(cmd.ResponseContext(), rule); err != nil {
+		return err
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf("%s now earns %s!", pointRuleMediaTypeName(rule.MediaType), formatPointRule(rule)),
+		},
+	)
+}
+
+func (c *GuildPointsCommand) handleRemove(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	unit, err := discordutil.GetRequiredStringOption(subcommand.Options, "unit")
+	if err != nil {
+		return err
+	}
+
+	mediaType := discordutil.GetStringOptionOrDefault(subcommand.Options, "media-type", "")
+
+	removed, err := c.rules.Delete(cmd.ResponseContext(), cmd.Interaction().GuildID, mediaType, unit)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("%s no longer earns points for %s.", pointRuleMediaTypeName(mediaType), unit)
+	if !removed {
+		content = fmt.Sprintf("%s does not earn points for %s!", pointRuleMediaTypeName(mediaType), unit)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: content,
+		},
+	)
+}
+
+func (c *GuildPointsCommand) handleList(cmd *bot.InteractionContext) error {
+	rules, err := c.rules.FindByGuildID(cmd.ResponseContext(), cmd.Interaction().GuildID)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) == 0 {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "This server does not award points! Members with Manage Server can add a rule with: `/guild-points set`",
+			},
+		)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Server Points").
+		SetColor(discordutil.ColorPrimary).
+		SetDescription("Media types with rules of their own only earn points from those rules.")
+
+	// rules are ordered by media type, so each type's rules are next to each other
+	var b strings.Builder
+	for i, rule := range rules {
+		fmt.Fprintf(&b, "%s\n", formatPointRule(rule))
+
+		if i == len(rules)-1 || rules[i+1].MediaType != rule.MediaType {
+			embed.AddField(pointRuleMediaTypeName(rule.MediaType), b.String(), false)
+			b.Reset()
+		}
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		},
+	)
+}
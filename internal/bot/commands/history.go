@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/users"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
 	"github.com/UTD-JLA/botsu/pkg/ref"
 	"github.com/bwmarrin/discordgo"
+	"github.com/golang-module/carbon/v2"
 )
 
 var HistoryCommandData = &discordgo.ApplicationCommand{
@@ -42,15 +45,154 @@ var HistoryCommandData = &discordgo.ApplicationCommand{
 			Description: "The page of history to view.",
 			Required:    false,
 		},
+		{
+			Name:        "media-type",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "Only show activities of this type of media.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "Visual Novel",
+					Value: activities.ActivityMediaTypeVisualNovel,
+				},
+				{
+					Name:  "Book",
+					Value: activities.ActivityMediaTypeBook,
+				},
+				{
+					Name:  "Manga",
+					Value: activities.ActivityMediaTypeManga,
+				},
+				{
+					Name:  "Anime",
+					Value: activities.ActivityMediaTypeAnime,
+				},
+				{
+					Name:  "Video",
+					Value: activities.ActivityMediaTypeVideo,
+				},
+			},
+		},
+		{
+			Name:        "activity-type",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "Only show activities of this type.",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "Listening",
+					Value: activities.ActivityImmersionTypeListening,
+				},
+				{
+					Name:  "Reading",
+					Value: activities.ActivityImmersionTypeReading,
+				},
+			},
+		},
+		{
+			Name:        "start",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "Only show activities on or after this date.",
+			Required:    false,
+		},
+		{
+			Name:        "end",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "Only show activities on or before this date.",
+			Required:    false,
+		},
+		{
+			Name:        "query",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "Only show activities whose name contains this text.",
+			Required:    false,
+			MaxLength:   100,
+		},
 	},
 }
 
 type HistoryCommand struct {
-	r *activities.ActivityRepository
+	r  *activities.ActivityRepository
+	u  *users.UserRepository
+	ts *users.UserTimeService
 }
 
-func NewHistoryCommand(r *activities.ActivityRepository) *HistoryCommand {
-	return &HistoryCommand{r: r}
+func NewHistoryCommand(r *activities.ActivityRepository, u *users.UserRepository, ts *users.UserTimeService) *HistoryCommand {
+	return &HistoryCommand{r: r, u: u, ts: ts}
+}
+
+// historyFilterDescription lists the filters applied to a history, or returns an empty string if there are none
+func historyFilterDescription(filter activities.ActivityFilter) string {
+	parts := make([]string, 0, 5)
+
+	if filter.MediaType != "" {
+		parts = append(parts, mediaTypeName(&filter.MediaType))
+	}
+
+	if filter.PrimaryType != "" {
+		parts = append(parts, activityTypeName(filter.PrimaryType))
+	}
+
+	if !filter.Start.IsZero() {
+		parts = append(parts, "from "+filter.Start.Format(time.DateOnly))
+	}
+
+	if !filter.End.IsZero() {
+		parts = append(parts, "until "+filter.End.Format(time.DateOnly))
+	}
+
+	if filter.Query != "" {
+		parts = append(parts, fmt.Sprintf("matching \"%s\"", filter.Query))
+	}
+
+	if len(parts) == 0 {
+		return ""
+	}
+
+	return "Showing " + strings.Join(parts, ", ")
+}
+
+// parseHistoryFilter reads the filter options of the command, interpreting dates in timezone,
+// and returns a message for the user if they are invalid
+func parseHistoryFilter(options []*discordgo.ApplicationCommandInteractionDataOption, timezone string) (filter activities.ActivityFilter, errorMsg string) {
+	filter.MediaType = discordutil.GetStringOptionOrDefault(options, "media-type", "")
+	filter.PrimaryType = discordutil.GetStringOptionOrDefault(options, "activity-type", "")
+	filter.Query = strings.TrimSpace(discordutil.GetStringOptionOrDefault(options, "query", ""))
+
+	startString := discordutil.GetStringOptionOrDefault(options, "start", "")
+	endString := discordutil.GetStringOptionOrDefault(options, "end", "")
+
+	var carbonStart, carbonEnd carbon.Carbon
+
+	if startString != "" {
+		carbonStart = carbon.SetTimezone(timezone).Parse(startString)
+
+		if !carbonStart.IsValid() {
+			return filter, "Invalid start date."
+		}
+	}
+
+	if endString != "" {
+		carbonEnd = carbon.SetTimezone(timezone).Parse(endString)
+
+		if !carbonEnd.IsValid() {
+			return filter, "Invalid end date."
+		}
+	}
+
+	if startString != "" && endString != "" && carbonEnd.Lt(carbonStart) {
+		carbonStart, carbonEnd = carbonEnd, carbonStart
+	}
+
+	if startString != "" {
+		filter.Start = carbonStart.StartOfDay().ToStdTime()
+	}
+
+	if endString != "" {
+		filter.End = carbonEnd.EndOfDay().ToStdTime()
+	}
+
+	return
 }
 
 func (c *HistoryCommand) Handle(ctx *bot.InteractionContext) error {
@@ -72,16 +214,46 @@ func (c *HistoryCommand) Handle(ctx *bot.InteractionContext) error {
 
 	if user == nil {
 		user = discordutil.GetInteractionUser(i)
+	} else if user.ID != discordutil.GetInteractionUser(i).ID {
+		privacy, err := c.u.GetPrivacy(ctx.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		if !privacy.AllowsActivities() {
+			return followupHidden(ctx, user, privacy)
+		}
+	}
+
+	// dates are in the timezone the history is shown in
+	timezone, err := c.ts.GetTimezone(ctx.Context(), user.ID, i.GuildID)
+	if err != nil {
+		return err
 	}
 
-	page, err := c.r.PageByUserID(ctx.Context(), user.ID, ctx.Interaction().GuildID, pageSize, offset)
+	filter, errorMsg := parseHistoryFilter(ctx.Options(), timezone)
+	if errorMsg != "" {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: errorMsg,
+		}, false)
+
+		return err
+	}
+
+	page, err := c.r.PageByUserIDFiltered(ctx.Context(), user.ID, ctx.Interaction().GuildID, filter, pageSize, offset)
 
 	if err != nil {
 		return err
 	}
 
+	description := historyFilterDescription(filter)
+	if len(page.Activities) == 0 && !filter.IsEmpty() {
+		description += "\nNo activities match these filters."
+	}
+
 	embed := discordutil.NewEmbedBuilder().
 		SetTitle("Activity History").
+		SetDescription(description).
 		SetColor(discordutil.ColorPrimary).
 		SetAuthor(user.Username, user.AvatarURL("256"), "").
 		SetFooter(fmt.Sprintf("Page %d of %d", page.Page, page.PageCount), "")
@@ -221,7 +393,7 @@ func (c *HistoryCommand) Handle(ctx *bot.InteractionContext) error {
 			offset = (page.PageCount - 1) * pageSize
 		}
 
-		page, err = c.r.PageByUserID(ciContext, user.ID, ctx.Interaction().GuildID, pageSize, offset)
+		page, err = c.r.PageByUserIDFiltered(ciContext, user.ID, ctx.Interaction().GuildID, filter, pageSize, offset)
 
 		if err != nil {
 			cancel()
@@ -7,12 +7,15 @@ import (
 	"log/slog"
 	"net/http"
 	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/users"
 	activitiesPub "github.com/UTD-JLA/botsu/pkg/activities"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
 	"github.com/bwmarrin/discordgo"
@@ -57,11 +60,21 @@ var ImportCommandData = &discordgo.ApplicationCommand{
 }
 
 type ImportCommand struct {
-	r *activities.ActivityRepository
+	r   *activities.ActivityRepository
+	gs  *goals.GoalService
+	ggs *goals.GuildGoalService
+	mrs *MilestoneRoleSyncer
+	ss  *users.StreakService
 }
 
-func NewImportCommand(r *activities.ActivityRepository) *ImportCommand {
-	return &ImportCommand{r}
+func NewImportCommand(
+	r *activities.ActivityRepository,
+	gs *goals.GoalService,
+	ggs *goals.GuildGoalService,
+	mrs *MilestoneRoleSyncer,
+	ss *users.StreakService,
+) *ImportCommand {
+	return &ImportCommand{r, gs, ggs, mrs, ss}
 }
 
 func (c *ImportCommand) handleList(
@@ -131,9 +144,9 @@ func (c *ImportCommand) handleUndo(
 		return err
 	}
 
-	var removed int64
+	removed, guildIDs, err := c.r.UndoImportByUserIDAndTimestamp(ctx, cmd.User().ID, time.Unix(0, timestamp))
 
-	if removed, err = c.r.UndoImportByUserIDAndTimestamp(ctx, cmd.User().ID, time.Unix(0, timestamp)); err != nil {
+	if err != nil {
 		embedBuilder.SetDescription("Failed to undo import!")
 
 		_, err = cmd.Followup(&discordgo.WebhookParams{
@@ -143,6 +156,30 @@ func (c *ImportCommand) handleUndo(
 		return err
 	}
 
+	if removed > 0 {
+		if _, err = c.gs.Recalculate(ctx, cmd.User().ID); err != nil {
+			return err
+		}
+
+		// take the removed activities back out of the goals of the guilds they were logged in
+		for _, guildID := range guildIDs {
+			if _, err = c.ggs.Recalculate(ctx, guildID); err != nil {
+				return err
+			}
+		}
+
+		if _, err = c.ss.Recalculate(ctx, cmd.User().ID, cmd.Interaction().GuildID); err != nil {
+			return err
+		}
+
+		// take away the milestone roles the user no longer has the hours for
+		for _, guildID := range guildIDs {
+			if _, err = c.mrs.Sync(ctx, cmd.Session(), guildID, cmd.User().ID); err != nil {
+				return err
+			}
+		}
+	}
+
 	if removed == 0 {
 		embedBuilder.SetDescription("No activities were removed. Make sure you are using the correct timestamp!")
 		embedBuilder.SetColor(discordutil.ColorWarning)
@@ -286,6 +323,30 @@ func (c *ImportCommand) Handle(cmd *bot.InteractionContext) error {
 		return err
 	}
 
+	if _, err := c.gs.Recalculate(ctx, cmd.User().ID); err != nil {
+		return err
+	}
+
+	guildIDs := activityGuildIDs(as)
+
+	// the imported activities count towards the goals of the guilds they were logged in
+	for _, guildID := range guildIDs {
+		if _, err := c.ggs.Recalculate(ctx, guildID); err != nil {
+			return err
+		}
+	}
+
+	if _, err := c.ss.Recalculate(ctx, cmd.User().ID, cmd.Interaction().GuildID); err != nil {
+		return err
+	}
+
+	// and towards the milestones of those guilds
+	for _, guildID := range guildIDs {
+		if _, err := c.mrs.Sync(ctx, cmd.Session(), guildID, cmd.User().ID); err != nil {
+			return err
+		}
+	}
+
 	embedBuilder.SetTitle("Success!")
 	embedBuilder.SetDescription(fmt.Sprintf("Successfully imported **%d** activities.\nView your import history with `/import list`.", len(as)))
 	embedBuilder.SetColor(discordutil.ColorSuccess)
@@ -297,3 +358,14 @@ func (c *ImportCommand) Handle(cmd *bot.InteractionContext) error {
 
 	return err
 }
+
+// activityGuildIDs returns the distinct IDs of the guilds the activities were logged in
+func activityGuildIDs(as []*activities.Activity) (guildIDs []string) {
+	for _, a := range as {
+		if a.GuildID != nil && !slices.Contains(guildIDs, *a.GuildID) {
+			guildIDs = append(guildIDs, *a.GuildID)
+		}
+	}
+
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
@@ -18,6 +19,82 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
+// options shared by every leaderboard period
+var leaderboardFilterOptions = []*discordgo.ApplicationCommandOption{
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "media-type",
+		Description: "Only count activities of this type of media.",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Visual Novel",
+				Value: activities.ActivityMediaTypeVisualNovel,
+			},
+			{
+				Name:  "Book",
+				Value: activities.ActivityMediaTypeBook,
+			},
+			{
+				Name:  "Manga",
+				Value: activities.ActivityMediaTypeManga,
+			},
+			{
+				Name:  "Anime",
+				Value: activities.ActivityMediaTypeAnime,
+			},
+			{
+				Name:  "Video",
+				Value: activities.ActivityMediaTypeVideo,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "activity-type",
+		Description: "Only count activities of this type.",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Listening",
+				Value: activities.ActivityImmersionTypeListening,
+			},
+			{
+				Name:  "Reading",
+				Value: activities.ActivityImmersionTypeReading,
+			},
+		},
+	},
+	{
+		Type:        discordgo.ApplicationCommandOptionString,
+		Name:        "metric",
+		Description: "What to rank members by (default duration).",
+		Required:    false,
+		Choices: []*discordgo.ApplicationCommandOptionChoice{
+			{
+				Name:  "Duration",
+				Value: activities.MetricDuration,
+			},
+			{
+				Name:  "Characters",
+				Value: activities.MetricCharacters,
+			},
+			{
+				Name:  "Pages",
+				Value: activities.MetricPages,
+			},
+			{
+				Name:  "Episodes",
+				Value: activities.MetricEpisodes,
+			},
+			{
+				Name:  "Points",
+				Value: activities.MetricPoints,
+			},
+		},
+	},
+}
+
 var LeaderboardCommandData = &discordgo.ApplicationCommand{
 	Name:         "leaderboard",
 	Description:  "View the leaderboard",
@@ -27,32 +104,37 @@ var LeaderboardCommandData = &discordgo.ApplicationCommand{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "day",
 			Description: "View the leaderboard for the current day",
+			Options:     leaderboardFilterOptions,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "week",
 			Description: "View the leaderboard for the current week",
+			Options:     leaderboardFilterOptions,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "month",
 			Description: "View the leaderboard for the current month",
+			Options:     leaderboardFilterOptions,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "year",
 			Description: "View the leaderboard for the current year",
+			Options:     leaderboardFilterOptions,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "all",
 			Description: "View the leaderboard for all time",
+			Options:     leaderboardFilterOptions,
 		},
 		{
 			Type:        discordgo.ApplicationCommandOptionSubCommand,
 			Name:        "custom",
 			Description: "View the leaderboard over a custom time period",
-			Options: []*discordgo.ApplicationCommandOption{
+			Options: append([]*discordgo.ApplicationCommandOption{
 				{
 					Type:        discordgo.ApplicationCommandOptionString,
 					Name:        "start",
@@ -65,7 +147,7 @@ var LeaderboardCommandData = &discordgo.ApplicationCommand{
 					Description: "The end date",
 					Required:    true,
 				},
-			},
+			}, leaderboardFilterOptions...),
 		},
 	},
 }
@@ -80,6 +162,88 @@ func NewLeaderboardCommand(r *activities.ActivityRepository, u *users.UserReposi
 	return &LeaderboardCommand{r: r, u: u, g: g}
 }
 
+// metricName returns the display name of a leaderboard metric
+func metricName(metric string) string {
+	switch metric {
+	case activities.MetricCharacters:
+		return "Characters"
+	case activities.MetricPages:
+		return "Pages"
+	case activities.MetricEpisodes:
+		return "Episodes"
+	case activities.MetricPoints:
+		return "Points"
+	default:
+		return "Duration"
+	}
+}
+
+// leaderboardTitle returns the title of a leaderboard, naming its filters
+func leaderboardTitle(filter activities.MemberFilter) string {
+	parts := make([]string, 0, 3)
+
+	if filter.MediaType != "" {
+		parts = append(parts, mediaTypeName(&filter.MediaType))
+	}
+
+	if filter.PrimaryType != "" {
+		parts = append(parts, activityTypeName(filter.PrimaryType))
+	}
+
+	if filter.Metric != "" && filter.Metric != activities.MetricDuration {
+		parts = append(parts, metricName(filter.Metric))
+	}
+
+	if len(parts) == 0 {
+		return "Leaderboard"
+	}
+
+	return fmt.Sprintf("Leaderboard (%s)", strings.Join(parts, ", "))
+}
+
+// leaderboardValue formats a member's total in the ranked metric
+func leaderboardValue(metric string, m *activities.MemberStats) string {
+	duration := m.TotalDuration.Truncate(time.Second).String()
+
+	if metric == "" || metric == activities.MetricDuration {
+		return duration
+	}
+
+	return fmt.Sprintf("%s (%s)", formatMetricValue(metric, m.Amount), duration)
+}
+
+// formatMetricValue formats an amount of a leaderboard metric, nanoseconds for duration
+func formatMetricValue(metric string, value int64) string {
+	if metric == "" || metric == activities.MetricDuration {
+		return time.Duration(value).Truncate(time.Second).String()
+	}
+
+	return fmt.Sprintf("%d %s", value, metric)
+}
+
+// leaderboardFooter returns the page number and the place of the user
+// with the given rank, which is nil if they are not on the leaderboard
+func leaderboardFooter(metric string, rank *activities.MemberRank, page *activities.MemberPage) string {
+	footer := fmt.Sprintf("Page %d of %d", max(page.Page, 1), max(page.PageCount, 1))
+
+	if rank == nil {
+		return footer + " • You are not on this leaderboard"
+	}
+
+	total := int64(rank.TotalDuration)
+	if metric != "" && metric != activities.MetricDuration {
+		total = rank.Amount
+	}
+
+	footer += fmt.Sprintf(" • You are #%d of %d with %s", rank.Rank, rank.MemberCount, formatMetricValue(metric, total))
+
+	if rank.Gap != nil {
+		footer += fmt.Sprintf(", %s behind the next place", formatMetricValue(metric, *rank.Gap))
+	}
+
+	return footer
+}
+
 func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 	if err := ctx.DeferResponse(); err != nil {
 		return err
@@ -92,14 +256,37 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 		return errors.New("this command can only be used in a guild")
 	}
 
+	guild, err := c.g.FindByID(ctx.Context(), i.GuildID)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	// the preset periods follow the guild's days and weeks
+	guildTimezone := carbon.UTC
+	weekStart := time.Sunday
+
+	if guild != nil {
+		if guild.Timezone != nil {
+			guildTimezone = *guild.Timezone
+		}
+
+		weekStart = guild.WeekStart
+	}
+
 	var start, end time.Time
-	now := carbon.Now(carbon.UTC)
+	now := carbon.Now(guildTimezone).SetWeekStartsAt(weekStart.String())
 
 	if len(ctx.Options()) == 0 {
 		return bot.ErrInvalidOptions
 	}
 
 	subcommand := ctx.Options()[0]
+	filter := activities.MemberFilter{
+		MediaType:   discordutil.GetStringOptionOrDefault(subcommand.Options, "media-type", ""),
+		PrimaryType: discordutil.GetStringOptionOrDefault(subcommand.Options, "activity-type", ""),
+		Metric:      discordutil.GetStringOptionOrDefault(subcommand.Options, "metric", activities.MetricDuration),
+	}
 
 	switch subcommand.Name {
 	case "day":
@@ -120,26 +307,15 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 	case "custom":
 		options := subcommand.Options
 		user, err := c.u.FindByID(ctx.Context(), i.Member.User.ID)
-		guildID := i.GuildID
 
 		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
 			return err
 		}
 
-		timezone := carbon.UTC
+		timezone := guildTimezone
 
 		if user != nil && user.Timezone != nil {
 			timezone = *user.Timezone
-		} else if guildID != "" {
-			guild, err := c.g.FindByID(ctx.ResponseContext(), guildID)
-
-			if err != nil && !errors.Is(err, pgx.ErrNoRows) {
-				return err
-			}
-
-			if guild != nil && guild.Timezone != nil {
-				timezone = *guild.Timezone
-			}
 		}
 
 		startString, err := discordutil.GetRequiredStringOption(options, "start")
@@ -183,17 +359,156 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 	}
 
 	// Note: Do not go over 100 members as Discord will not allow fetching 100+ in a single chunk
-	topMembers, err := c.r.GetTopMembers(ctx.Context(), i.GuildID, 10, start, end)
+	const pageSize = 10
+	offset := 0
+
+	page, err := c.r.PageTopMembers(ctx.Context(), i.GuildID, start, end, filter, pageSize, offset)
 
 	if err != nil {
 		return err
 	}
 
-	missingMembers := make([]string, 0, len(topMembers))
+	rank, err := c.r.GetMemberRank(ctx.Context(), i.GuildID, ctx.User().ID, start, end, filter)
+
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	description := fmt.Sprintf("Starting <t:%d:R>, resetting <t:%d:R>.", start.Unix(), end.Unix())
+
+	embed := discordutil.NewEmbedBuilder().
+		SetDescription(description).
+		SetTitle(leaderboardTitle(filter)).
+		SetColor(discordutil.ColorPrimary).
+		SetTimestamp(time.Now()).
+		SetFooter(leaderboardFooter(filter.Metric, rank, page), "")
+
+	if err = c.addMemberFields(ctx, embed, page.Members, filter.Metric); err != nil {
+		return err
+	}
+
+	if page.PageCount <= 1 {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		}, false)
+
+		return err
+	}
+
+	nextButton := discordgo.Button{
+		Label:    "Next",
+		Style:    discordgo.PrimaryButton,
+		CustomID: "leaderboard_next",
+	}
+
+	previousButton := discordgo.Button{
+		Label:    "Previous",
+		Style:    discordgo.SecondaryButton,
+		CustomID: "leaderboard_previous",
+		Disabled: true,
+	}
+
+	msg, err := ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+		Components: []discordgo.MessageComponent{
+			discordgo.ActionsRow{
+				Components: []discordgo.MessageComponent{
+					previousButton,
+					nextButton,
+				},
+			},
+		},
+	}, true)
+
+	if err != nil {
+		return err
+	}
+
+	collectionContext, cancel := context.WithTimeout(ctx.Context(), 2*time.Minute)
+
+	defer cancel()
+
+	interactions, err := ctx.Bot.NewMessageComponentInteractionChannel(
+		collectionContext,
+		msg,
+		discordutil.NewInteractionUserFilter(i),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for ci := range interactions {
+		ciContext, cancel := context.WithDeadline(ctx.Context(), discordutil.GetInteractionResponseDeadline(ci.Interaction))
+
+		if ci.MessageComponentData().CustomID == "leaderboard_previous" {
+			offset = max(offset-pageSize, 0)
+		} else if ci.MessageComponentData().CustomID == "leaderboard_next" {
+			offset += pageSize
+		}
+
+		page, err = c.r.PageTopMembers(ciContext, i.GuildID, start, end, filter, pageSize, offset)
+
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		embed.SetFooter(leaderboardFooter(filter.Metric, rank, page), "")
+		embed.ClearFields()
+
+		if err = c.addMemberFields(ctx, embed, page.Members, filter.Metric); err != nil {
+			cancel()
+			return err
+		}
+
+		previousButton.Disabled = page.Page <= 1
+		nextButton.Disabled = page.Page >= page.PageCount
+
+		err = s.InteractionRespond(ci.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+				Components: []discordgo.MessageComponent{
+					discordgo.ActionsRow{
+						Components: []discordgo.MessageComponent{
+							previousButton,
+							nextButton,
+						},
+					},
+				},
+			},
+		})
+
+		cancel()
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ctx.Session().InteractionResponseEdit(ctx.Interaction().Interaction, &discordgo.WebhookEdit{
+		Components: &[]discordgo.MessageComponent{},
+	})
+
+	return err
+}
+
+// addMemberFields adds a field with the place and total of each member to the embed,
+// removing members who have left the guild
+func (c *LeaderboardCommand) addMemberFields(
+	ctx *bot.InteractionContext,
+	embed *discordutil.EmbedBuilder,
+	members []*activities.MemberStats,
+	metric string,
+) error {
+	s := ctx.Session()
+	guildID := ctx.Interaction().GuildID
+	missingMembers := make([]string, 0, len(members))
 	foundMembers := make(map[string]*discordgo.Member)
 
-	for _, m := range topMembers {
-		guild, err := s.State.Guild(i.GuildID)
+	for _, m := range members {
+		guild, err := s.State.Guild(guildID)
 
 		if err != nil {
 			missingMembers = append(missingMembers, m.UserID)
@@ -227,7 +542,7 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 
 		defer removeHandler()
 
-		err = s.RequestGuildMembersList(i.GuildID, missingMembers, 0, nonce, false)
+		err = s.RequestGuildMembersList(guildID, missingMembers, 0, nonce, false)
 
 		if err != nil {
 			return err
@@ -245,17 +560,9 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 		}
 	}
 
-	description := fmt.Sprintf("Starting <t:%d:R>, resetting <t:%d:R>.", start.Unix(), end.Unix())
-
-	embed := discordutil.NewEmbedBuilder().
-		SetDescription(description).
-		SetTitle("Leaderboard").
-		SetColor(discordutil.ColorPrimary).
-		SetTimestamp(time.Now())
-
-	deadMembers := make([]string, 0, len(topMembers))
+	deadMembers := make([]string, 0, len(members))
 
-	for x, m := range topMembers {
+	for _, m := range members {
 		member, ok := foundMembers[m.UserID]
 		displayName := m.UserID
 		if ok && member.Nick != "" {
@@ -273,21 +580,19 @@ func (c *LeaderboardCommand) Handle(ctx *bot.InteractionContext) error {
 			displayName = usr.Username
 		}
 
-		title := fmt.Sprintf("%d. %s", x+1, displayName)
-		value := m.TotalDuration.Truncate(time.Second).String()
+		title := fmt.Sprintf("%d. %s", m.Rank, displayName)
+		value := leaderboardValue(metric, m)
 
 		embed.AddField(title, value, false)
 	}
 
-	go func() {
-		if err = c.g.RemoveMembers(context.Background(), i.GuildID, deadMembers); err != nil {
-			ctx.Logger.Error("Failed to remove members", slog.String("err", err.Error()))
-		}
-	}()
-
-	_, err = ctx.Followup(&discordgo.WebhookParams{
-		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
-	}, false)
+	if len(deadMembers) > 0 {
+		go func() {
+			if err := c.g.RemoveMembers(context.Background(), guildID, deadMembers); err != nil {
+				ctx.Logger.Error("Failed to remove members", slog.String("err", err.Error()))
+			}
+		}()
+	}
 
-	return err
+	return nil
 }
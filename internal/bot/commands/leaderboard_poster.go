@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/guilds"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/adhocore/gronx"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+// number of members shown on scheduled leaderboards
+const leaderboardPostSize = 10
+
+// LeaderboardPoster posts the leaderboard of each finished period
+// in the channel of guilds that have scheduled one
+type LeaderboardPoster struct {
+	activities *activities.ActivityRepository
+	guilds     *guilds.GuildRepository
+	logger     *slog.Logger
+}
+
+func NewLeaderboardPoster(activities *activities.ActivityRepository, guilds *guilds.GuildRepository, logger *slog.Logger) *LeaderboardPoster {
+	return &LeaderboardPoster{activities: activities, guilds: guilds, logger: logger}
+}
+
+// leaderboardPlace is a member's place on a posted leaderboard
+// and, unless they are new to it, their place the period before
+type leaderboardPlace struct {
+	*activities.MemberStats
+	previous *activities.MemberRank
+}
+
+// Send posts the leaderboards that are due, returning the number of leaderboards posted
+func (p *LeaderboardPoster) Send(ctx context.Context, s *discordgo.Session) (sent int, err error) {
+	due, err := p.guilds.FindDueLeaderboards(ctx, time.Now())
+	if err != nil {
+		return
+	}
+
+	for _, g := range due {
+		posted, postErr := p.post(ctx, s, g)
+		if postErr != nil {
+			p.logger.Error(
+				"Unable to post leaderboard",
+				slog.String("guild_id", g.ID),
+				slog.String("err", postErr.Error()),
+			)
+			continue
+		}
+
+		if posted {
+			sent++
+		}
+	}
+
+	return
+}
+
+// post posts the leaderboard of the guild's last finished period,
+// returning false if it was not posted by this call
+func (p *LeaderboardPoster) post(ctx context.Context, s *discordgo.Session, g *guilds.Guild) (bool, error) {
+	cron := *g.LeaderboardCron
+
+	location := time.UTC
+	if g.Timezone != nil {
+		var err error
+		if location, err = time.LoadLocation(*g.Timezone); err != nil {
+			return false, err
+		}
+	}
+
+	now := time.Now().In(location)
+
+	next, err := gronx.NextTickAfter(cron, now, false)
+	if err != nil {
+		return false, err
+	}
+
+	// if the bot was down for several periods, only the last one is posted
+	end, err := gronx.PrevTickBefore(cron, now, true)
+	if err != nil {
+		return false, err
+	}
+
+	start, err := gronx.PrevTickBefore(cron, end, false)
+	if err != nil {
+		return false, err
+	}
+
+	previousStart, err := gronx.PrevTickBefore(cron, start, false)
+	if err != nil {
+		return false, err
+	}
+
+	// the ends of ranges are inclusive, and timestamps are stored to the microsecond
+	places, err := p.findPlaces(ctx, g.ID, start, end.Add(-time.Microsecond), previousStart, start.Add(-time.Microsecond))
+	if err != nil {
+		return false, err
+	}
+
+	// claimed only once the leaderboard is ready so a failure above is retried next time,
+	// see GoalReminderSender.Send
+	claimed, err := p.guilds.ClaimLeaderboard(ctx, g.ID, *g.LeaderboardDueAt, next)
+	if err != nil || !claimed {
+		return false, err
+	}
+
+	_, err = s.ChannelMessageSendComplex(*g.LeaderboardChannelID, &discordgo.MessageSend{
+		Embeds:          []*discordgo.MessageEmbed{newLeaderboardPostEmbed(start, end, next, places).MessageEmbed},
+		AllowedMentions: &discordgo.MessageAllowedMentions{},
+	})
+
+	if err != nil {
+		// the channel may have been deleted or made inaccessible
+		p.logger.Warn(
+			"Unable to post leaderboard",
+			slog.String("guild_id", g.ID),
+			slog.String("channel_id", *g.LeaderboardChannelID),
+			slog.String("err", err.Error()),
+		)
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// findPlaces returns the top members between start and end
+// along with their ranks between previousStart and previousEnd
+func (p *LeaderboardPoster) findPlaces(ctx context.Context, guildID string, start, end, previousStart, previousEnd time.Time) ([]leaderboardPlace, error) {
+	members, err := p.activities.GetTopMembers(ctx, guildID, leaderboardPostSize, start, end, activities.MemberFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]leaderboardPlace, 0, len(members))
+
+	for _, m := range members {
+		previous, err := p.activities.GetMemberRank(ctx, guildID, m.UserID, previousStart, previousEnd, activities.MemberFilter{})
+		if errors.Is(err, pgx.ErrNoRows) {
+			previous = nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		places = append(places, leaderboardPlace{MemberStats: m, previous: previous})
+	}
+
+	return places, nil
+}
+
+// formatPlaceChange describes how a member's time and rank changed since the previous period
+func formatPlaceChange(place leaderboardPlace) string {
+	if place.previous == nil {
+		return "new"
+	}
+
+	difference := (place.TotalDuration - place.previous.TotalDuration).Truncate(time.Second)
+	change := difference.String()
+	if difference >= 0 {
+		change = "+" + change
+	}
+
+	switch moved := place.previous.Rank - place.Rank; {
+	case moved > 0:
+		return fmt.Sprintf("%s, ▲%d", change, moved)
+	case moved < 0:
+		return fmt.Sprintf("%s, ▼%d", change, -moved)
+	default:
+		return change
+	}
+}
+
+func newLeaderboardPostEmbed(start, end, next time.Time, places []leaderboardPlace) *discordutil.EmbedBuilder {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From <t:%d:f> to <t:%d:f>\n\n", start.Unix(), end.Unix())
+
+	for _, place := range places {
+		fmt.Fprintf(
+			&b,
+			"**%d.** <@%s>: %s (%s)\n",
+			place.Rank,
+			place.UserID,
+			place.TotalDuration.Truncate(time.Second),
+			formatPlaceChange(place),
+		)
+	}
+
+	if len(places) == 0 {
+		b.WriteString("No one logged any activities this period.")
+	}
+
+	return discordutil.NewEmbedBuilder().
+		SetTitle(leaderboardTitle(activities.MemberFilter{})).
+		SetColor(discordutil.ColorPrimary).
+		SetDescription(b.String()).
+		SetFooter("Next leaderboard", "").
+		SetTimestamp(next)
+}
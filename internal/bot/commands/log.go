@@ -8,12 +8,14 @@ import (
 	"image"
 	"image/jpeg"
 	"io"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/UTD-JLA/botsu/internal/achievements"
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
 	"github.com/UTD-JLA/botsu/internal/goals"
@@ -298,7 +300,12 @@ type LogCommand struct {
 	guildRepo     *guilds.GuildRepository
 	mediaSearcher *mediadata.MediaSearcher
 	goalService   *goals.GoalService
+	guildGoals    *goals.GuildGoalService
 	timeService   *users.UserTimeService
+	pointRules    *activities.PointRuleRepository
+	milestones    *MilestoneRoleSyncer
+	streaks       *users.StreakService
+	achievements  *achievements.AchievementService
 	ytClient      youtube.Client
 }
 
@@ -308,7 +315,12 @@ func NewLogCommand(
 	gr *guilds.GuildRepository,
 	ms *mediadata.MediaSearcher,
 	gs *goals.GoalService,
+	ggs *goals.GuildGoalService,
 	ts *users.UserTimeService,
+	pr *activities.PointRuleRepository,
+	mrs *MilestoneRoleSyncer,
+	ss *users.StreakService,
+	as *achievements.AchievementService,
 ) *LogCommand {
 	return &LogCommand{
 		activityRepo:  ar,
@@ -316,7 +328,12 @@ func NewLogCommand(
 		mediaSearcher: ms,
 		guildRepo:     gr,
 		goalService:   gs,
+		guildGoals:    ggs,
 		timeService:   ts,
+		pointRules:    pr,
+		milestones:    mrs,
+		streaks:       ss,
+		achievements:  as,
 		ytClient:      youtube.Client{},
 	}
 }
@@ -350,7 +367,70 @@ func (c *LogCommand) Handle(ctx *bot.InteractionContext) error {
 	}
 }
 
+// addLogFields adds the user's streak to the confirmation of a logged activity, and the
+// points the activity earns if the guild it was logged in awards any
+func (c *LogCommand) addLogFields(ctx context.Context, embed *discordutil.EmbedBuilder, a *activities.Activity) error {
+	guildID := ""
+	if a.GuildID != nil {
+		guildID = *a.GuildID
+	}
+
+	if guildID != "" {
+		rules, err := c.pointRules.FindByGuildID(ctx, guildID)
+		if err != nil {
+			return err
+		}
+
+		if len(rules) > 0 {
+			points := math.Round(activities.Points(rules, a)*100) / 100
+			embed.AddField("Points", fmt.Sprintf("%g", points), false)
+		}
+	}
+
+	streak, err := c.streaks.Update(ctx, a.UserID, guildID)
+	if err != nil {
+		return err
+	}
+
+	embed.AddField("Streak", formatStreak(streak), false)
+	return nil
+}
+
+// afterLog updates everything that depends on the user's activities once one is logged
+func (c *LogCommand) afterLog(cmd *bot.InteractionContext, a *activities.Activity) error {
+	if a.GuildID != nil {
+		if _, err := c.milestones.Sync(cmd.Context(), cmd.Session(), *a.GuildID, a.UserID); err != nil {
+			return err
+		}
+	}
+
+	if err := c.checkGoals(cmd, a); err != nil {
+		return err
+	}
+
+	return c.checkAchievements(cmd, a)
+}
+
+// checkAchievements must be called after the user's streak is updated
+func (c *LogCommand) checkAchievements(cmd *bot.InteractionContext, a *activities.Activity) error {
+	unlocked, err := c.achievements.Check(cmd.Context(), a.UserID, cmd.Interaction().GuildID)
+	if err != nil || len(unlocked) == 0 {
+		return err
+	}
+
+	_, err = cmd.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{newAchievementsUnlockedEmbed(a, unlocked).MessageEmbed},
+	}, false)
+
+	return err
+}
+
 func (c *LogCommand) checkGoals(cmd *bot.InteractionContext, a *activities.Activity) error {
+	// completed guild goals are announced by GuildGoalAnnouncer
+	if _, err := c.guildGoals.CheckCompleted(cmd.Context(), a); err != nil {
+		return err
+	}
+
 	completedGoals, err := c.goalService.CheckCompleted(cmd.Context(), a)
 	if err != nil {
 		return err
@@ -359,6 +439,14 @@ func (c *LogCommand) checkGoals(cmd *bot.InteractionContext, a *activities.Activ
 		return nil
 	}
 
+	_, err = cmd.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{newGoalsCompletedEmbed(a, completedGoals).MessageEmbed},
+	}, false)
+
+	return err
+}
+
+func newGoalsCompletedEmbed(a *activities.Activity, completedGoals []*goals.Goal) *discordutil.EmbedBuilder {
 	embed := discordutil.NewEmbedBuilder().
 		SetTitle("Goals completed!").
 		SetColor(discordutil.ColorSuccess).
@@ -372,14 +460,10 @@ func (c *LogCommand) checkGoals(cmd *bot.InteractionContext, a *activities.Activ
 			break
 		}
 
-		embed.AddField(g.Name, fmt.Sprintf("Target: %s\nCompleted: %s", g.Target.String(), g.Current.String()), false)
+		embed.AddField(g.Name, fmt.Sprintf("Target: %s\nCompleted: %s", g.FormatValue(g.Target), g.FormatValue(g.Current)), false)
 	}
 
-	_, err = cmd.Followup(&discordgo.WebhookParams{
-		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
-	}, false)
-
-	return err
+	return embed
 }
 
 func (c *LogCommand) handleAutocomplete(ctx context.Context, s *discordgo.Session, i *discordgo.InteractionCreate) error {
@@ -512,8 +596,11 @@ func (c *LogCommand) handleAnime(ctx *bot.InteractionContext, subcommand *discor
 		SetFooter(fmt.Sprintf("ID: %d", activity.ID), "").
 		SetThumbnail(thumbnail).
 		SetTimestamp(activity.Date).
-		SetColor(discordutil.ColorSuccess).
-		MessageEmbed
+		SetColor(discordutil.ColorSuccess)
+
+	if err = c.addLogFields(ctx.Context(), embed, activity); err != nil {
+		return err
+	}
 
 	row := discordgo.ActionsRow{
 		Components: []discordgo.MessageComponent{},
@@ -529,7 +616,7 @@ func (c *LogCommand) handleAnime(ctx *bot.InteractionContext, subcommand *discor
 	}
 
 	params := discordgo.WebhookParams{
-		Embeds: []*discordgo.MessageEmbed{embed},
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
 	}
 
 	if len(row.Components) > 0 {
@@ -541,7 +628,7 @@ func (c *LogCommand) handleAnime(ctx *bot.InteractionContext, subcommand *discor
 		return err
 	}
 
-	return c.checkGoals(ctx, activity)
+	return c.afterLog(ctx, activity)
 }
 
 func (c *LogCommand) handleBook(ctx *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
@@ -556,6 +643,10 @@ func (c *LogCommand) handleBook(ctx *bot.InteractionContext, subcommand *discord
 		Date     string `discordopt:"date"`
 	}
 
+	if err := discordutil.UnmarshalOptions(subcommand.Options, &args); err != nil {
+		return err
+	}
+
 	userID := discordutil.GetInteractionUser(ctx.Interaction()).ID
 	guildID := ctx.Interaction().GuildID
 
@@ -632,6 +723,10 @@ func (c *LogCommand) handleBook(ctx *bot.InteractionContext, subcommand *discord
 		embed.AddField("Pages Read", fmt.Sprintf("%d", pageCount), false)
 	}
 
+	if err := c.addLogFields(ctx.Context(), embed, activity); err != nil {
+		return err
+	}
+
 	_, err := ctx.Followup(&discordgo.WebhookParams{
 		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
 	}, false)
@@ -639,7 +734,7 @@ func (c *LogCommand) handleBook(ctx *bot.InteractionContext, subcommand *discord
 		return err
 	}
 
-	return c.checkGoals(ctx, activity)
+	return c.afterLog(ctx, activity)
 }
 
 func (c *LogCommand) handleVisualNovel(ctx *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
@@ -781,6 +876,10 @@ func (c *LogCommand) handleVisualNovel(ctx *bot.InteractionContext, subcommand *
 		embed.AddField("Characters Read", fmt.Sprintf("%d", charCount), false)
 	}
 
+	if err = c.addLogFields(ctx.Context(), embed, activity); err != nil {
+		return err
+	}
+
 	_, err = ctx.Followup(&discordgo.WebhookParams{
 		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
 		Files:  attachments,
@@ -790,7 +889,7 @@ func (c *LogCommand) handleVisualNovel(ctx *bot.InteractionContext, subcommand *
 		return err
 	}
 
-	return c.checkGoals(ctx, activity)
+	return c.afterLog(ctx, activity)
 }
 
 func (c *LogCommand) handleVideo(ctx *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
@@ -912,6 +1011,10 @@ func (c *LogCommand) handleVideo(ctx *bot.InteractionContext, subcommand *discor
 		SetTimestamp(activity.Date).
 		SetColor(discordutil.ColorSuccess)
 
+	if err = c.addLogFields(ctx.Context(), embed, activity); err != nil {
+		return err
+	}
+
 	row := discordgo.ActionsRow{
 		Components: []discordgo.MessageComponent{
 			discordgo.Button{
@@ -948,10 +1051,14 @@ func (c *LogCommand) handleVideo(ctx *bot.InteractionContext, subcommand *discor
 		return err
 	}
 
-	return c.checkGoals(ctx, activity)
+	return c.afterLog(ctx, activity)
 }
 
 func (c *LogCommand) handleManual(ctx *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if err := ctx.DeferResponse(); err != nil {
+		return err
+	}
+
 	userID := discordutil.GetInteractionUser(ctx.Interaction()).ID
 	guildID := ctx.Interaction().GuildID
 
@@ -988,9 +1095,10 @@ func (c *LogCommand) handleManual(ctx *bot.InteractionContext, subcommand *disco
 
 		activity.Date, err = time.ParseInLocation(time.DateTime, args.Date, location)
 		if err != nil {
-			return ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			_, err = ctx.Followup(&discordgo.WebhookParams{
 				Content: "Invalid date provided.",
-			})
+			}, false)
+			return err
 		}
 	}
 
@@ -1005,17 +1113,20 @@ func (c *LogCommand) handleManual(ctx *bot.InteractionContext, subcommand *disco
 		AddField("Duration", activity.Duration.String(), false).
 		SetFooter(fmt.Sprintf("ID: %d", activity.ID), "").
 		SetTimestamp(activity.Date).
-		SetColor(discordutil.ColorSuccess).
-		MessageEmbed
+		SetColor(discordutil.ColorSuccess)
 
-	err = ctx.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
-		Embeds: []*discordgo.MessageEmbed{embed},
-	})
+	if err = c.addLogFields(ctx.Context(), embed, activity); err != nil {
+		return err
+	}
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	}, false)
 	if err != nil {
 		return err
 	}
 
-	return c.checkGoals(ctx, activity)
+	return c.afterLog(ctx, activity)
 }
 
 func (c *LogCommand) createAutocompleteResult(ctx context.Context, mediaType, input string) (choices []*discordgo.ApplicationCommandOptionChoice, err error) {
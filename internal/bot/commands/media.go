@@ -0,0 +1,196 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/mediadata"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+var MediaCommandData = &discordgo.ApplicationCommand{
+	Name:        "media",
+	Description: "View your totals for a title",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Name:         "title",
+			Type:         discordgo.ApplicationCommandOptionString,
+			Description:  "The title to look up.",
+			Required:     true,
+			Autocomplete: true,
+		},
+	},
+}
+
+const (
+	// number of the user's own titles suggested before anime and visual novels
+	mediaAutocompleteOwnTitles = 5
+	// number of other members listed as having logged the title
+	mediaMemberCount = 10
+)
+
+type MediaCommand struct {
+	r  *activities.ActivityRepository
+	ms *mediadata.MediaSearcher
+}
+
+func NewMediaCommand(r *activities.ActivityRepository, ms *mediadata.MediaSearcher) *MediaCommand {
+	return &MediaCommand{r: r, ms: ms}
+}
+
+func (c *MediaCommand) handleAutocomplete(ctx *bot.InteractionContext) error {
+	input := discordutil.GetStringOptionOrDefault(ctx.Options(), "title", "")
+	user := discordutil.GetInteractionUser(ctx.Interaction())
+
+	titles, err := c.r.SearchTitlesByUserID(ctx.ResponseContext(), user.ID, input, mediaAutocompleteOwnTitles)
+	if err != nil {
+		return err
+	}
+
+	choices := make([]*discordgo.ApplicationCommandOptionChoice, 0, 25)
+	seen := make(map[string]bool)
+
+	for _, title := range titles {
+		// names are used as the key of works without an ID, which can not be cut short
+		if len(title.Key) > 100 {
+			continue
+		}
+
+		seen[title.Key] = true
+		choices = append(choices, &discordgo.ApplicationCommandOptionChoice{
+			Name:  truncateLongString(title.Title, 100),
+			Value: title.Key,
+		})
+	}
+
+	results, err := createGoalTitleAutocompleteResult(ctx.ResponseContext(), c.ms, "", input)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if len(choices) == 25 {
+			break
+		}
+
+		if key, _ := result.Value.(string); !seen[key] {
+			choices = append(choices, result)
+		}
+	}
+
+	return ctx.Respond(discordgo.InteractionApplicationCommandAutocompleteResult, &discordgo.InteractionResponseData{
+		Choices: choices,
+	})
+}
+
+// titleOfKey returns the title of the anime or visual novel with the given
+// media key, or the key itself if it is a name or the work can not be found
+func (c *MediaCommand) titleOfKey(ctx context.Context, key string) string {
+	if id, ok := strings.CutPrefix(key, goalTitlePrefixAnidb); ok {
+		if anime, err := c.ms.ReadAnime(ctx, id); err == nil {
+			return anime.PrimaryTitle
+		}
+	} else if id, ok := strings.CutPrefix(key, goalTitlePrefixVndb); ok {
+		if vn, err := c.ms.ReadVisualNovel(ctx, id); err == nil {
+			return vn.JapaneseTitle
+		}
+	}
+
+	return key
+}
+
+func (c *MediaCommand) Handle(ctx *bot.InteractionContext) error {
+	if ctx.IsAutocomplete() {
+		return c.handleAutocomplete(ctx)
+	}
+
+	key, err := discordutil.GetRequiredStringOption(ctx.Options(), "title")
+	if err != nil {
+		return err
+	}
+
+	if err = ctx.DeferResponse(); err != nil {
+		return err
+	}
+
+	user := discordutil.GetInteractionUser(ctx.Interaction())
+	guildID := ctx.Interaction().GuildID
+
+	stats, err := c.r.GetMediaStatsByUserID(ctx.Context(), user.ID, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("You have not logged **%s**!", c.titleOfKey(ctx.Context(), key)),
+		}, false)
+
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(truncateLongString(stats.Title, 256)).
+		SetAuthor(user.Username, user.AvatarURL("256"), "").
+		SetColor(discordutil.ColorPrimary).
+		AddField("Total Time", stats.Total.Truncate(time.Second).String(), true).
+		AddField("Sessions", fmt.Sprintf("%d", stats.Count), true).
+		AddField("Media Type", mediaTypeName(stats.MediaType), true).
+		AddField("First Logged", fmt.Sprintf("<t:%d:D>", stats.First.Unix()), true).
+		AddField("Last Logged", fmt.Sprintf("<t:%d:D>", stats.Last.Unix()), true)
+
+	// visual novel thumbnails may be NSFW and are only shown blurred when logging
+	isVN := stats.MediaType != nil && *stats.MediaType == activities.ActivityMediaTypeVisualNovel
+	if stats.Thumbnail != nil && !isVN {
+		embed.SetThumbnail(*stats.Thumbnail)
+	}
+
+	metaTotals := map[string]int64{
+		"characters": stats.Characters,
+		"pages":      stats.Pages,
+		"episodes":   stats.Episodes,
+	}
+
+	for _, field := range editableMetaFields {
+		if total := metaTotals[field.key]; total > 0 {
+			embed.AddField(field.label, fmt.Sprintf("%d", total), true)
+		}
+	}
+
+	if guildID != "" {
+		// one more than shown, in case the user is among them
+		members, err := c.r.GetMediaMembersByGuildID(ctx.Context(), guildID, key, mediaMemberCount+1)
+		if err != nil {
+			return err
+		}
+
+		var b strings.Builder
+		shown := 0
+
+		for _, member := range members {
+			if member.UserID == user.ID || shown == mediaMemberCount {
+				continue
+			}
+
+			shown++
+			fmt.Fprintf(&b, "%d. <@%s>: %s\n", shown, member.UserID, member.TotalDuration.Truncate(time.Second))
+		}
+
+		if shown == 0 {
+			b.WriteString("No one else here has logged this title yet.")
+		}
+
+		embed.AddField("Also Logged By", b.String(), false)
+	}
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	}, false)
+
+	return err
+}
@@ -0,0 +1,322 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/guilds"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/bwmarrin/discordgo"
+)
+
+var GuildMilestonesCommandData = &discordgo.ApplicationCommand{
+	Name:         "guild-milestones",
+	Description:  "Manage the roles members are given for the hours they have logged.",
+	DMPermission: ref.New(false),
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "set",
+			Description: "Give a role to members once they have logged some hours (requires Manage Server).",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to give.",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionInteger,
+					Name:        "hours",
+					Description: "The total hours members must have logged.",
+					MinValue:    ref.New(1.0),
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "remove",
+			Description: "Stop giving a role for logged hours (requires Manage Server).",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionRole,
+					Name:        "role",
+					Description: "The role to stop giving.",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "list",
+			Description: "List the server's milestone roles.",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "sync",
+			Description: "Give and take away milestone roles of every member (requires Manage Server).",
+		},
+	},
+}
+
+// MilestoneRoleSyncer gives members the milestone roles of the hours
+// they have logged, and takes away those they no longer have
+type MilestoneRoleSyncer struct {
+	activities *activities.ActivityRepository
+	guilds     *guilds.GuildRepository
+	logger     *slog.Logger
+}
+
+func NewMilestoneRoleSyncer(activities *activities.ActivityRepository, guilds *guilds.GuildRepository, logger *slog.Logger) *MilestoneRoleSyncer {
+	return &MilestoneRoleSyncer{activities: activities, guilds: guilds, logger: logger}
+}
+
+// Sync updates the milestone roles of the member with userID, or of every
+// member of the guild if it is empty, returning the number of roles given or taken away
+func (m *MilestoneRoleSyncer) Sync(ctx context.Context, s *discordgo.Session, guildID, userID string) (changed int, err error) {
+	roles, err := m.guilds.FindMilestoneRoles(ctx, guildID)
+	if err != nil || len(roles) == 0 {
+		return
+	}
+
+	totals, err := m.activities.GetLifetimeTotalsByGuildID(ctx, guildID, userID)
+	if err != nil {
+		return
+	}
+
+	for memberID, total := range totals {
+		member, memberErr := s.State.Member(guildID, memberID)
+		if memberErr != nil {
+			member, memberErr = s.GuildMember(guildID, memberID, discordgo.WithContext(ctx))
+		}
+
+		if memberErr != nil {
+			// the member may have left the guild
+			m.logger.Debug(
+				"Unable to find member",
+				slog.String("guild_id", guildID),
+				slog.String("user_id", memberID),
+				slog.String("err", memberErr.Error()),
+			)
+			continue
+		}
+
+		add, remove := guilds.MilestoneRoleChanges(roles, total, member.Roles)
+
+		for _, roleID := range add {
+			if err := s.GuildMemberRoleAdd(guildID, memberID, roleID, discordgo.WithContext(ctx)); err != nil {
+				m.warnRoleChange("Unable to give milestone role", guildID, memberID, roleID, err)
+				continue
+			}
+
+			changed++
+		}
+
+		for _, roleID := range remove {
+			if err := s.GuildMemberRoleRemove(guildID, memberID, roleID, discordgo.WithContext(ctx)); err != nil {
+				m.warnRoleChange("Unable to take away milestone role", guildID, memberID, roleID, err)
+				continue
+			}
+
+			changed++
+		}
+	}
+
+	return
+}
+
+// warnRoleChange logs a failed role change, the bot may be missing the
+// Manage Roles permission or the role may be above the bot's own
+func (m *MilestoneRoleSyncer) warnRoleChange(msg, guildID, userID, roleID string, err error) {
+	m.logger.Warn(
+		msg,
+		slog.String("guild_id", guildID),
+		slog.String("user_id", userID),
+		slog.String("role_id", roleID),
+		slog.String("err", err.Error()),
+	)
+}
+
+type GuildMilestonesCommand struct {
+	guilds     *guilds.GuildRepository
+	milestones *MilestoneRoleSyncer
+}
+
+func NewGuildMilestonesCommand(guilds *guilds.GuildRepository, milestones *MilestoneRoleSyncer) *GuildMilestonesCommand {
+	return &GuildMilestonesCommand{guilds: guilds, milestones: milestones}
+}
+
+func (c *GuildMilestonesCommand) Handle(cmd *bot.InteractionContext) error {
+	if len(cmd.Options()) == 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	subcommand := cmd.Options()[0]
+
+	switch subcommand.Name {
+	case "set":
+		return c.handleSet(cmd, subcommand)
+	case "remove":
+		return c.handleRemove(cmd, subcommand)
+	case "list":
+		return c.handleList(cmd)
+	case "sync":
+		return c.handleSync(cmd)
+	default:
+		return bot.ErrInvalidOptions
+	}
+}
+
+func (c *GuildMilestonesCommand) handleSet(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	guildID := cmd.Interaction().GuildID
+
+	role, err := discordutil.GetRequiredRoleOption(subcommand.Options, "role", cmd.Session(), guildID)
+	if err != nil {
+		return err
+	}
+
+	hours, err := discordutil.GetRequiredIntOption(subcommand.Options, "hours")
+	if err != nil {
+		return err
+	}
+
+	if role.Managed || role.ID == guildID {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "That role can not be given to members.",
+				Flags:   discordgo.MessageFlagsEphemeral,
+			},
+		)
+	}
+
+	// guilds are otherwise only created once someone logs an activity in them
+	if _, err = c.guilds.FindOrCreate(cmd.ResponseContext(), guildID); err != nil {
+		return err
+	}
+
+	err = c.guilds.SetMilestoneRole(cmd.ResponseContext(), &guilds.MilestoneRole{
+		GuildID: guildID,
+		RoleID:  role.ID,
+		Hours:   int(hours),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content: fmt.Sprintf(
+				"Members will be given <@&%s> once they have logged %d hours! Use `/guild-milestones sync` to give it to those who already have.",
+				role.ID,
+				hours,
+			),
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	)
+}
+
+func (c *GuildMilestonesCommand) handleRemove(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	guildID := cmd.Interaction().GuildID
+
+	role, err := discordutil.GetRequiredRoleOption(subcommand.Options, "role", cmd.Session(), guildID)
+	if err != nil {
+		return err
+	}
+
+	removed, err := c.guilds.DeleteMilestoneRole(cmd.ResponseContext(), guildID, role.ID)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf("Members will no longer be given <@&%s>. Members who have it keep it.", role.ID)
+	if !removed {
+		content = fmt.Sprintf("<@&%s> is not a milestone role!", role.ID)
+	}
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Content:         content,
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	)
+}
+
+func (c *GuildMilestonesCommand) handleList(cmd *bot.InteractionContext) error {
+	roles, err := c.guilds.FindMilestoneRoles(cmd.ResponseContext(), cmd.Interaction().GuildID)
+	if err != nil {
+		return err
+	}
+
+	if len(roles) == 0 {
+		return cmd.Respond(
+			discordgo.InteractionResponseChannelMessageWithSource,
+			&discordgo.InteractionResponseData{
+				Content: "This server has no milestone roles! Members with Manage Server can add one with: `/guild-milestones set`",
+			},
+		)
+	}
+
+	var b strings.Builder
+	for _, role := range roles {
+		fmt.Fprintf(&b, "**%dh**: <@&%s>\n", role.Hours, role.RoleID)
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Milestone Roles").
+		SetColor(discordutil.ColorPrimary).
+		SetDescription(b.String())
+
+	return cmd.Respond(
+		discordgo.InteractionResponseChannelMessageWithSource,
+		&discordgo.InteractionResponseData{
+			Embeds:          []*discordgo.MessageEmbed{embed.MessageEmbed},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		},
+	)
+}
+
+func (c *GuildMilestonesCommand) handleSync(cmd *bot.InteractionContext) error {
+	if !canManageGuild(cmd) {
+		return respondMissingPermission(cmd)
+	}
+
+	if err := cmd.DeferResponse(); err != nil {
+		return err
+	}
+
+	start := time.Now()
+
+	changed, err := c.milestones.Sync(cmd.Context(), cmd.Session(), cmd.Interaction().GuildID, "")
+	if err != nil {
+		return err
+	}
+
+	_, err = cmd.Followup(&discordgo.WebhookParams{
+		Content: fmt.Sprintf(
+			"Gave or took away %d milestone roles in %s. If some were not changed, make sure the bot has the Manage Roles permission and that its role is above them.",
+			changed,
+			time.Since(start).Truncate(time.Second),
+		),
+	}, false)
+
+	return err
+}
@@ -0,0 +1,39 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/bwmarrin/discordgo"
+)
+
+// isResolvedGuildMember reports whether the user picked in an option
+// of the command is a member of the guild it was used in
+func isResolvedGuildMember(i *discordgo.InteractionCreate, userID string) bool {
+	if i.GuildID == "" || i.Type != discordgo.InteractionApplicationCommand {
+		return false
+	}
+
+	resolved := i.ApplicationCommandData().Resolved
+	if resolved == nil {
+		return false
+	}
+
+	_, ok := resolved.Members[userID]
+	return ok
+}
+
+// followupHidden tells the user of the command that the activity of user is hidden from them
+func followupHidden(ctx *bot.InteractionContext, user *discordgo.User, privacy users.Privacy) error {
+	content := fmt.Sprintf("**%s** keeps their activity private!", user.Username)
+	if privacy == users.PrivacyGuild {
+		content = fmt.Sprintf("**%s** only shares the totals of their activity with members of their guilds!", user.Username)
+	}
+
+	_, err := ctx.Followup(&discordgo.WebhookParams{
+		Content: content,
+	}, false)
+
+	return err
+}
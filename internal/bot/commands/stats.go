@@ -0,0 +1,228 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+	"github.com/golang-module/carbon/v2"
+)
+
+var StatsCommandData = &discordgo.ApplicationCommand{
+	Name:        "stats",
+	Description: "View a summary of your activity",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Name:        "user",
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Description: "The user to view the stats of (defaults to yourself).",
+			Required:    false,
+		},
+		{
+			Name:        "range",
+			Type:        discordgo.ApplicationCommandOptionString,
+			Description: "The time range to summarize (defaults to this month).",
+			Required:    false,
+			Choices: []*discordgo.ApplicationCommandOptionChoice{
+				{
+					Name:  "This week",
+					Value: "week",
+				},
+				{
+					Name:  "This month",
+					Value: "month",
+				},
+				{
+					Name:  "This year",
+					Value: "year",
+				},
+				{
+					Name:  "All time",
+					Value: "all",
+				},
+			},
+		},
+	},
+}
+
+// number of titles shown in the top titles field
+const statsTopTitles = 5
+
+type StatsCommand struct {
+	r       *activities.ActivityRepository
+	u       *users.UserRepository
+	ts      *users.UserTimeService
+	streaks *users.StreakService
+}
+
+func NewStatsCommand(r *activities.ActivityRepository, u *users.UserRepository, ts *users.UserTimeService, ss *users.StreakService) *StatsCommand {
+	return &StatsCommand{r: r, u: u, ts: ts, streaks: ss}
+}
+
+// statsRange returns the bounds and name of a range option in the given timezone
+func statsRange(name, timezone string) (start, end time.Time, label string) {
+	now := carbon.Now(timezone)
+
+	switch name {
+	case "week":
+		return now.StartOfWeek().ToStdTime(), now.EndOfWeek().ToStdTime(), "This Week"
+	case "year":
+		return now.StartOfYear().ToStdTime(), now.EndOfYear().ToStdTime(), "This Year"
+	case "all":
+		return time.Unix(0, 0), now.ToStdTime(), "All Time"
+	default:
+		return now.StartOfMonth().ToStdTime(), now.EndOfMonth().ToStdTime(), "This Month"
+	}
+}
+
+// speedUnit returns the unit of the speed meta of activities of the media type
+func speedUnit(mediaType string) string {
+	if mediaType == activities.ActivityMediaTypeVisualNovel {
+		return "chars/min"
+	}
+
+	return "pages/min"
+}
+
+func (c *StatsCommand) Handle(ctx *bot.InteractionContext) error {
+	if err := ctx.DeferResponse(); err != nil {
+		return err
+	}
+
+	user := discordutil.GetUserOptionOrDefault(
+		ctx.Options(),
+		"user",
+		discordutil.GetInteractionUser(ctx.Interaction()),
+		ctx.Session(),
+	)
+
+	guildID := ctx.Interaction().GuildID
+	rangeName := discordutil.GetStringOptionOrDefault(ctx.Options(), "range", "month")
+	// titles are hidden from others unless the user's activity is public
+	showTitles := true
+
+	if user.ID != discordutil.GetInteractionUser(ctx.Interaction()).ID {
+		privacy, err := c.u.GetPrivacy(ctx.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		if !privacy.AllowsTotals(isResolvedGuildMember(ctx.Interaction(), user.ID)) {
+			return followupHidden(ctx, user, privacy)
+		}
+
+		showTitles = privacy.AllowsActivities()
+	}
+
+	timezone, err := c.ts.GetTimezone(ctx.Context(), user.ID, guildID)
+	if err != nil {
+		return err
+	}
+
+	now := carbon.Now(timezone).ToStdTime()
+	start, end, rangeLabel := statsRange(rangeName, timezone)
+
+	allTime, err := c.r.GetSummaryByUserID(ctx.Context(), user.ID, time.Unix(0, 0), now)
+	if err != nil {
+		return err
+	}
+
+	if allTime.Count == 0 {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("%s has no activity!", user.Username),
+		}, false)
+
+		return err
+	}
+
+	summary, err := c.r.GetSummaryByUserID(ctx.Context(), user.ID, start, end)
+	if err != nil {
+		return err
+	}
+
+	mediaTotals, err := c.r.GetTotalByUserIDGroupedByMediaType(ctx.Context(), user.ID, start, end)
+	if err != nil {
+		return err
+	}
+
+	streak, err := c.streaks.Update(ctx.Context(), user.ID, guildID)
+	if err != nil {
+		return err
+	}
+
+	titles, err := c.r.GetTopTitlesByUserID(ctx.Context(), user.ID, start, end, statsTopTitles)
+	if err != nil {
+		return err
+	}
+
+	speeds, err := c.r.GetAvgSpeedByUserIDGroupedByMediaType(ctx.Context(), user.ID, start, end)
+	if err != nil {
+		return err
+	}
+
+	// average over the days of the range that have passed, all time starts at the first activity
+	averageStart := start
+	if rangeName == "all" {
+		averageStart = *allTime.First
+	}
+
+	days := int(now.Sub(averageStart).Hours()/24) + 1
+	dailyAverage := summary.Total / time.Duration(days)
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Stats for %s", user.Username)).
+		SetThumbnail(user.AvatarURL("")).
+		SetColor(discordutil.ColorPrimary).
+		SetTimestamp(time.Now()).
+		AddField("All Time", allTime.Total.Truncate(time.Second).String(), true).
+		AddField(rangeLabel, summary.Total.Truncate(time.Second).String(), true).
+		AddField("Daily Average", dailyAverage.Truncate(time.Second).String(), true).
+		AddField("Activities", fmt.Sprintf("%d", summary.Count), true).
+		AddField("Current Streak", fmt.Sprintf("%d days", streak.Current), true).
+		AddField("Best Streak", fmt.Sprintf("%d days", streak.Best), true)
+
+	if rangeName != "all" {
+		embed.SetDescription(fmt.Sprintf("%s is from <t:%d:D> to <t:%d:D>.", rangeLabel, start.Unix(), end.Unix()))
+	}
+
+	if mediaTotals.Len() > 0 {
+		var b strings.Builder
+		for _, mediaType := range mediaTotals.Keys() {
+			total, _ := mediaTotals.Get(mediaType)
+			fmt.Fprintf(&b, "%s: %s\n", mediaTypeName(&mediaType), total.Truncate(time.Second))
+		}
+
+		embed.AddField("By Media Type", b.String(), false)
+	}
+
+	if len(titles) > 0 && showTitles {
+		var b strings.Builder
+		for i, title := range titles {
+			fmt.Fprintf(&b, "%d. %s: %s\n", i+1, truncateLongString(title.Title, 80), title.Total.Truncate(time.Second))
+		}
+
+		embed.AddField("Top Titles", b.String(), false)
+	}
+
+	if len(speeds) > 0 {
+		var b strings.Builder
+		for _, mediaType := range breakdownMediaTypes {
+			if speed, ok := speeds[mediaType]; ok {
+				fmt.Fprintf(&b, "%s: %.2f %s\n", mediaTypeName(&mediaType), speed, speedUnit(mediaType))
+			}
+		}
+
+		embed.AddField("Average Speed", b.String(), false)
+	}
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	}, false)
+
+	return err
+}
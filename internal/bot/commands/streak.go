@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+var StreakCommandData = &discordgo.ApplicationCommand{
+	Name:        "streak",
+	Description: "View your daily immersion streak",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Name:        "user",
+			Type:        discordgo.ApplicationCommandOptionUser,
+			Description: "The user to view the streak of (defaults to yourself).",
+			Required:    false,
+		},
+	},
+}
+
+type StreakCommand struct {
+	u       *users.UserRepository
+	streaks *users.StreakService
+}
+
+func NewStreakCommand(u *users.UserRepository, streaks *users.StreakService) *StreakCommand {
+	return &StreakCommand{u: u, streaks: streaks}
+}
+
+// formatStreak describes the length of a streak and whether today counts towards it yet
+func formatStreak(streak *users.Streak) string {
+	days := "days"
+	if streak.Current == 1 {
+		days = "day"
+	}
+
+	if streak.TodayMet {
+		return fmt.Sprintf("🔥 %d %s (best %d)", streak.Current, days, streak.Best)
+	}
+
+	return fmt.Sprintf("%d %s (best %d), today does not count yet", streak.Current, days, streak.Best)
+}
+
+func (c *StreakCommand) Handle(ctx *bot.InteractionContext) error {
+	if err := ctx.DeferResponse(); err != nil {
+		return err
+	}
+
+	i := ctx.Interaction()
+	user := discordutil.GetUserOptionOrDefault(ctx.Options(), "user", discordutil.GetInteractionUser(i), ctx.Session())
+
+	if user.ID != discordutil.GetInteractionUser(i).ID {
+		privacy, err := c.u.GetPrivacy(ctx.Context(), user.ID)
+		if err != nil {
+			return err
+		}
+
+		if !privacy.AllowsTotals(isResolvedGuildMember(i, user.ID)) {
+			return followupHidden(ctx, user, privacy)
+		}
+	}
+
+	streak, err := c.streaks.Update(ctx.Context(), user.ID, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	dailyGoal := "Any activity"
+	freezes := 0
+
+	u, err := c.u.FindByID(ctx.Context(), user.ID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	} else if err == nil {
+		if u.DailyGoal > 0 {
+			dailyGoal = (time.Duration(u.DailyGoal) * time.Minute).String()
+		}
+
+		freezes = u.StreakFreezes
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Streak of %s", user.Username)).
+		SetThumbnail(user.AvatarURL("")).
+		SetColor(discordutil.ColorPrimary).
+		AddField("Current Streak", formatStreak(streak), false).
+		AddField("Best Streak", fmt.Sprintf("%d days", streak.Best), true).
+		AddField("Daily Goal", dailyGoal, true)
+
+	if freezes > 0 {
+		embed.AddField("Freezes Left", fmt.Sprintf("%d of %d this month", streak.FreezesLeft, freezes), true)
+	}
+
+	embed.SetFooter("Set your daily goal and streak freezes with /config", "")
+
+	_, err = ctx.Followup(&discordgo.WebhookParams{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	}, false)
+
+	return err
+}
@@ -0,0 +1,310 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/achievements"
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/timers"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/bwmarrin/discordgo"
+	"github.com/jackc/pgx/v5"
+)
+
+var TimerCommandData = &discordgo.ApplicationCommand{
+	Name:        "timer",
+	Description: "Time your immersion as you go",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "start",
+			Description: "Start a new timer",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "name",
+					Description: "Title/name of the activity",
+					Required:    true,
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "type",
+					Description: "Type of activity (listening/reading)",
+					Required:    true,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Listening",
+							Value: activities.ActivityImmersionTypeListening,
+						},
+						{
+							Name:  "Reading",
+							Value: activities.ActivityImmersionTypeReading,
+						},
+					},
+				},
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "media-type",
+					Description: "Type of media of the activity",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{
+							Name:  "Anime",
+							Value: activities.ActivityMediaTypeAnime,
+						},
+						{
+							Name:  "Manga",
+							Value: activities.ActivityMediaTypeManga,
+						},
+						{
+							Name:  "Book",
+							Value: activities.ActivityMediaTypeBook,
+						},
+						{
+							Name:  "Video",
+							Value: activities.ActivityMediaTypeVideo,
+						},
+						{
+							Name:  "Visual Novel",
+							Value: activities.ActivityMediaTypeVisualNovel,
+						},
+					},
+				},
+			},
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "pause",
+			Description: "Pause your running timer",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "resume",
+			Description: "Resume your paused timer",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "stop",
+			Description: "Stop your timer and log the activity",
+		},
+		{
+			Type:        discordgo.ApplicationCommandOptionSubCommand,
+			Name:        "status",
+			Description: "View your running timer",
+		},
+	},
+}
+
+type TimerCommand struct {
+	timers       *timers.TimerService
+	milestones   *MilestoneRoleSyncer
+	streaks      *users.StreakService
+	achievements *achievements.AchievementService
+}
+
+func NewTimerCommand(ts *timers.TimerService, mrs *MilestoneRoleSyncer, ss *users.StreakService, as *achievements.AchievementService) *TimerCommand {
+	return &TimerCommand{timers: ts, milestones: mrs, streaks: ss, achievements: as}
+}
+
+func (c *TimerCommand) Handle(cmd *bot.InteractionContext) error {
+	if len(cmd.Options()) == 0 {
+		return bot.ErrInvalidOptions
+	}
+
+	subcommand := cmd.Options()[0]
+
+	if subcommand.Name == "start" {
+		return c.handleStart(cmd, subcommand)
+	}
+
+	timer, err := c.timers.FindRunningByUserID(cmd.ResponseContext(), cmd.User().ID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "You do not have a running timer! Start one with `/timer start`.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	} else if err != nil {
+		return err
+	}
+
+	switch subcommand.Name {
+	case "pause":
+		return c.handlePause(cmd, timer)
+	case "resume":
+		return c.handleResume(cmd, timer)
+	case "stop":
+		return c.handleStop(cmd, timer)
+	case "status":
+		return c.handleStatus(cmd, timer)
+	default:
+		return bot.ErrInvalidOptions
+	}
+}
+
+func (c *TimerCommand) handleStart(cmd *bot.InteractionContext, subcommand *discordgo.ApplicationCommandInteractionDataOption) error {
+	name, err := discordutil.GetRequiredStringOption(subcommand.Options, "name")
+	if err != nil {
+		return err
+	}
+
+	activityType, err := discordutil.GetRequiredStringOption(subcommand.Options, "type")
+	if err != nil {
+		return err
+	}
+
+	timer := timers.NewTimer(cmd.User().ID, name, activityType, time.Now())
+	timer.MediaType = discordutil.GetStringOption(subcommand.Options, "media-type")
+
+	if guildID := cmd.Interaction().GuildID; guildID != "" {
+		timer.GuildID = &guildID
+	}
+
+	err = c.timers.Create(cmd.ResponseContext(), timer)
+
+	if errors.Is(err, timers.ErrTimerAlreadyRunning) {
+		return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "You already have a running timer! Stop it with `/timer stop` before starting a new one.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	} else if err != nil {
+		return err
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Timer started!").
+		SetDescription(fmt.Sprintf(
+			"Use `/timer stop` when you are done. Timers running longer than %s are stopped automatically.",
+			c.timers.MaxDuration,
+		)).
+		AddField("Title", timer.Name, false).
+		AddField("Started", fmt.Sprintf("<t:%d:R>", timer.StartedAt.Unix()), false).
+		SetFooter(fmt.Sprintf("Timer ID: %d", timer.ID), "").
+		SetColor(discordutil.ColorSuccess)
+
+	return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	})
+}
+
+func (c *TimerCommand) handlePause(cmd *bot.InteractionContext, timer *timers.Timer) error {
+	if timer.IsPaused() {
+		return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "Your timer is already paused.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	now := time.Now()
+	timer.Pause(now)
+
+	if err := c.timers.UpdatePause(cmd.ResponseContext(), timer); err != nil {
+		return err
+	}
+
+	return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Content: fmt.Sprintf("Timer paused at **%s**. Use `/timer resume` to continue.", timer.Elapsed(now).Truncate(time.Second)),
+	})
+}
+
+func (c *TimerCommand) handleResume(cmd *bot.InteractionContext, timer *timers.Timer) error {
+	if !timer.IsPaused() {
+		return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+			Content: "Your timer is not paused.",
+			Flags:   discordgo.MessageFlagsEphemeral,
+		})
+	}
+
+	now := time.Now()
+	timer.Resume(now)
+
+	if err := c.timers.UpdatePause(cmd.ResponseContext(), timer); err != nil {
+		return err
+	}
+
+	return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Content: fmt.Sprintf("Timer resumed at **%s**.", timer.Elapsed(now).Truncate(time.Second)),
+	})
+}
+
+func (c *TimerCommand) handleStatus(cmd *bot.InteractionContext, timer *timers.Timer) error {
+	status := "Running"
+	if timer.IsPaused() {
+		status = "Paused"
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Timer").
+		AddField("Title", timer.Name, false).
+		AddField("Status", status, true).
+		AddField("Elapsed", timer.Elapsed(time.Now()).Truncate(time.Second).String(), true).
+		AddField("Started", fmt.Sprintf("<t:%d:R>", timer.StartedAt.Unix()), true).
+		SetFooter(fmt.Sprintf("Timer ID: %d", timer.ID), "").
+		SetColor(discordutil.ColorPrimary)
+
+	return cmd.Respond(discordgo.InteractionResponseChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Embeds: []*discordgo.MessageEmbed{embed.MessageEmbed},
+	})
+}
+
+func (c *TimerCommand) handleStop(cmd *bot.InteractionContext, timer *timers.Timer) error {
+	if err := cmd.DeferResponse(); err != nil {
+		return err
+	}
+
+	activity, completedGoals, err := c.timers.StopAndLog(cmd.Context(), timer, time.Now())
+
+	if errors.Is(err, timers.ErrTimerAlreadyStopped) {
+		_, err = cmd.Followup(&discordgo.WebhookParams{
+			Content: "Your timer has already been stopped.",
+		}, false)
+		return err
+	} else if err != nil {
+		return err
+	}
+
+	if activity.GuildID != nil {
+		if _, err = c.milestones.Sync(cmd.Context(), cmd.Session(), *activity.GuildID, activity.UserID); err != nil {
+			return err
+		}
+	}
+
+	streak, err := c.streaks.Update(cmd.Context(), activity.UserID, cmd.Interaction().GuildID)
+	if err != nil {
+		return err
+	}
+
+	unlocked, err := c.achievements.Check(cmd.Context(), activity.UserID, cmd.Interaction().GuildID)
+	if err != nil {
+		return err
+	}
+
+	embed := discordutil.NewEmbedBuilder().
+		SetTitle("Activity logged!").
+		AddField("Title", activity.Name, false).
+		AddField("Duration", activity.Duration.Truncate(time.Second).String(), false).
+		AddField("Streak", formatStreak(streak), false).
+		SetFooter(fmt.Sprintf("ID: %d", activity.ID), "").
+		SetTimestamp(activity.Date).
+		SetColor(discordutil.ColorSuccess)
+
+	embeds := []*discordgo.MessageEmbed{embed.MessageEmbed}
+
+	if len(completedGoals) > 0 {
+		embeds = append(embeds, newGoalsCompletedEmbed(activity, completedGoals).MessageEmbed)
+	}
+
+	if len(unlocked) > 0 {
+		embeds = append(embeds, newAchievementsUnlockedEmbed(activity, unlocked).MessageEmbed)
+	}
+
+	_, err = cmd.Followup(&discordgo.WebhookParams{
+		Embeds: embeds,
+	}, false)
+
+	return err
+}
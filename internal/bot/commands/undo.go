@@ -8,6 +8,8 @@ import (
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/users"
 	"github.com/UTD-JLA/botsu/pkg/discordutil"
 	"github.com/UTD-JLA/botsu/pkg/ref"
 	"github.com/bwmarrin/discordgo"
@@ -29,11 +31,21 @@ var UndoCommandData = &discordgo.ApplicationCommand{
 }
 
 type UndoCommand struct {
-	r *activities.ActivityRepository
+	r   *activities.ActivityRepository
+	gs  *goals.GoalService
+	ggs *goals.GuildGoalService
+	ss  *users.StreakService
+	mrs *MilestoneRoleSyncer
 }
 
-func NewUndoCommand(r *activities.ActivityRepository) *UndoCommand {
-	return &UndoCommand{r: r}
+func NewUndoCommand(
+	r *activities.ActivityRepository,
+	gs *goals.GoalService,
+	ggs *goals.GuildGoalService,
+	ss *users.StreakService,
+	mrs *MilestoneRoleSyncer,
+) *UndoCommand {
+	return &UndoCommand{r: r, gs: gs, ggs: ggs, ss: ss, mrs: mrs}
 }
 
 func (c *UndoCommand) Handle(ctx *bot.InteractionContext) error {
@@ -145,6 +157,17 @@ func (c *UndoCommand) undoActivity(ctx *bot.InteractionContext, id uint64) error
 			return err
 		}
 
+		// take the removed activity back out of the user's goals
+		if _, err = c.gs.Recalculate(ctx.Context(), activity.UserID); err != nil {
+			return err
+		}
+
+		if activity.GuildID != nil {
+			if _, err = c.ggs.Recalculate(ctx.Context(), *activity.GuildID); err != nil {
+				return err
+			}
+		}
+
 		err := ctx.Session().InteractionRespond(ci.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseUpdateMessage,
 			Data: &discordgo.InteractionResponseData{
@@ -154,7 +177,23 @@ func (c *UndoCommand) undoActivity(ctx *bot.InteractionContext, id uint64) error
 			},
 		})
 
-		return err
+		if err != nil {
+			return err
+		}
+
+		// the removed activity may have been the only one of a day in the streak
+		if _, err = c.ss.Recalculate(ctx.Context(), activity.UserID, ctx.Interaction().GuildID); err != nil {
+			return err
+		}
+
+		// take away the milestone roles the user no longer has the hours for
+		if activity.GuildID != nil {
+			if _, err = c.mrs.Sync(ctx.Context(), ctx.Session(), *activity.GuildID, activity.UserID); err != nil {
+				return err
+			}
+		}
+
+		return nil
 	} else if ci.MessageComponentData().CustomID == "undo_cancel" {
 		err := ctx.Session().InteractionRespond(ci.Interaction, &discordgo.InteractionResponse{
 			Type: discordgo.InteractionResponseUpdateMessage,
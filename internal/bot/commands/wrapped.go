@@ -0,0 +1,428 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/bot"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/UTD-JLA/botsu/pkg/chart"
+	"github.com/UTD-JLA/botsu/pkg/discordutil"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/bwmarrin/discordgo"
+	"github.com/golang-module/carbon/v2"
+)
+
+var WrappedCommandData = &discordgo.ApplicationCommand{
+	Name:        "wrapped",
+	Description: "View a summary of your year of immersion",
+	Options: []*discordgo.ApplicationCommandOption{
+		{
+			Name:        "year",
+			Type:        discordgo.ApplicationCommandOptionInteger,
+			Description: "The year to summarize (defaults to this year).",
+			MinValue:    ref.New(2000.0),
+			MaxValue:    9999,
+			Required:    false,
+		},
+	},
+}
+
+// number of titles and channels listed on their pages
+const wrappedTopCount = 10
+
+type WrappedCommand struct {
+	r        *activities.ActivityRepository
+	gr       *goals.GoalRepository
+	ts       *users.UserTimeService
+	renderer ChartRenderer
+}
+
+func NewWrappedCommand(
+	r *activities.ActivityRepository,
+	gr *goals.GoalRepository,
+	ts *users.UserTimeService,
+	renderer ChartRenderer,
+) *WrappedCommand {
+	return &WrappedCommand{r: r, gr: gr, ts: ts, renderer: renderer}
+}
+
+// wrappedReport is everything shown in a user's wrapped
+type wrappedReport struct {
+	Year           int
+	Summary        *activities.UserSummary
+	Titles         []*activities.TitleStats
+	ChannelNames   []string
+	ChannelTotals  []time.Duration
+	Months         [12]time.Duration
+	Weekdays       [7]time.Duration
+	MediaTypes     []string
+	MediaTotals    []time.Duration
+	GoalsCompleted int
+}
+
+// busiestMonth returns the month with the highest total, January if there is none
+func (w *wrappedReport) busiestMonth() time.Month {
+	busiest := 0
+	for i, total := range w.Months {
+		if total > w.Months[busiest] {
+			busiest = i
+		}
+	}
+
+	return time.Month(busiest + 1)
+}
+
+// busiestWeekday returns the weekday with the highest total, Sunday if there is none
+func (w *wrappedReport) busiestWeekday() time.Weekday {
+	busiest := time.Sunday
+	for i, total := range w.Weekdays {
+		if total > w.Weekdays[busiest] {
+			busiest = time.Weekday(i)
+		}
+	}
+
+	return busiest
+}
+
+func (c *WrappedCommand) getReport(ctx context.Context, userID, guildID string, year int, timezone string) (*wrappedReport, error) {
+	start := carbon.CreateFromDate(year, 1, 1, timezone).StartOfYear().ToStdTime()
+	end := carbon.CreateFromDate(year, 1, 1, timezone).EndOfYear().ToStdTime()
+
+	report := &wrappedReport{Year: year}
+
+	var err error
+
+	if report.Summary, err = c.r.GetSummaryByUserID(ctx, userID, start, end); err != nil {
+		return nil, err
+	}
+
+	if report.Summary.Count == 0 {
+		return report, nil
+	}
+
+	if report.Titles, err = c.r.GetTopTitlesByUserID(ctx, userID, start, end, wrappedTopCount); err != nil {
+		return nil, err
+	}
+
+	channels, err := c.r.GetTotalByUserIDGroupByVideoChannel(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, channel := range channels.Keys() {
+		if len(report.ChannelNames) == wrappedTopCount {
+			break
+		}
+
+		total, _ := channels.Get(channel)
+		report.ChannelNames = append(report.ChannelNames, channel)
+		report.ChannelTotals = append(report.ChannelTotals, total)
+	}
+
+	// the months are matched by date in the user's timezone
+	months, err := c.r.GetTotalByUserIDGroupedByMonth(
+		ctx,
+		userID,
+		guildID,
+		time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(year, time.December, 1, 0, 0, 0, 0, time.UTC),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, total := range months.Values() {
+		if i < len(report.Months) {
+			report.Months[i] = total
+		}
+	}
+
+	if report.Weekdays, err = c.r.GetTotalByUserIDGroupedByWeekday(ctx, userID, guildID, start, end); err != nil {
+		return nil, err
+	}
+
+	mediaTotals, err := c.r.GetTotalByUserIDGroupedByMediaType(ctx, userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report.MediaTypes = mediaTotals.Keys()
+	report.MediaTotals = mediaTotals.Values()
+
+	if report.GoalsCompleted, err = c.gr.CountMetPeriodsByUserID(ctx, userID, start, end); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// cardStats returns the highlights of the report drawn on its image card
+func (w *wrappedReport) cardStats() []chart.CardStat {
+	topTitle := "-"
+	if len(w.Titles) > 0 {
+		topTitle = w.Titles[0].Title
+	}
+
+	return []chart.CardStat{
+		{Label: "Total Time", Value: w.Summary.Total.Truncate(time.Minute).String()},
+		{Label: "Activities", Value: fmt.Sprintf("%d", w.Summary.Count)},
+		{Label: "Busiest Month", Value: w.busiestMonth().String()},
+		{Label: "Busiest Weekday", Value: w.busiestWeekday().String()},
+		{Label: "Goals Completed", Value: fmt.Sprintf("%d", w.GoalsCompleted)},
+		{Label: "Top Title", Value: topTitle},
+	}
+}
+
+// pages returns the embeds of the report, the first one showing the image card
+func (w *wrappedReport) pages(user *discordgo.User) []*discordgo.MessageEmbed {
+	title := fmt.Sprintf("%s's %d Wrapped", user.Username, w.Year)
+
+	overview := discordutil.NewEmbedBuilder().
+		SetTitle(title).
+		SetColor(discordutil.ColorPrimary).
+		SetThumbnail(user.AvatarURL("")).
+		SetImage("attachment://wrapped.png")
+
+	for _, stat := range w.cardStats() {
+		overview.AddField(stat.Label, truncateLongString(stat.Value, 80), true)
+	}
+
+	pages := []*discordgo.MessageEmbed{overview.MessageEmbed}
+
+	if len(w.Titles) > 0 {
+		var b strings.Builder
+		for i, t := range w.Titles {
+			fmt.Fprintf(&b, "%d. %s: %s\n", i+1, truncateLongString(t.Title, 80), t.Total.Truncate(time.Second))
+		}
+
+		pages = append(pages, discordutil.NewEmbedBuilder().
+			SetTitle(title+": Top Titles").
+			SetColor(discordutil.ColorSecondary).
+			SetDescription(b.String()).
+			MessageEmbed)
+	}
+
+	if len(w.ChannelNames) > 0 {
+		var b strings.Builder
+		for i, channel := range w.ChannelNames {
+			fmt.Fprintf(&b, "%d. [%s](https://www.youtube.com/%s): %s\n", i+1, channel, channel, w.ChannelTotals[i].Truncate(time.Second))
+		}
+
+		pages = append(pages, discordutil.NewEmbedBuilder().
+			SetTitle(title+": Top YouTube Channels").
+			SetColor(discordutil.ColorInfo).
+			SetDescription(b.String()).
+			MessageEmbed)
+	}
+
+	var months strings.Builder
+	for i, total := range w.Months {
+		fmt.Fprintf(&months, "%s: %s\n", time.Month(i+1), total.Truncate(time.Second))
+	}
+
+	var weekdays strings.Builder
+	for i, total := range w.Weekdays {
+		fmt.Fprintf(&weekdays, "%s: %s\n", time.Weekday(i), total.Truncate(time.Second))
+	}
+
+	pages = append(pages, discordutil.NewEmbedBuilder().
+		SetTitle(title+": Busiest Times").
+		SetColor(discordutil.ColorWarning).
+		SetDescription(fmt.Sprintf(
+			"Your busiest month was **%s** and your busiest weekday was **%s**.",
+			w.busiestMonth(),
+			w.busiestWeekday(),
+		)).
+		AddField("By Month", months.String(), true).
+		AddField("By Weekday", weekdays.String(), true).
+		MessageEmbed)
+
+	var media strings.Builder
+	for i, mediaType := range w.MediaTypes {
+		fmt.Fprintf(&media, "%s: %s\n", mediaTypeName(&mediaType), w.MediaTotals[i].Truncate(time.Second))
+	}
+
+	pages = append(pages, discordutil.NewEmbedBuilder().
+		SetTitle(title+": Media").
+		SetColor(discordutil.ColorSuccess).
+		SetDescription(media.String()).
+		AddField("Goals Completed", fmt.Sprintf("%d", w.GoalsCompleted), false).
+		MessageEmbed)
+
+	return pages
+}
+
+// wrappedComponents returns the navigation and share buttons for the given page
+func wrappedComponents(page, pageCount int, shared bool) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.Button{
+					Label:    "Previous",
+					Style:    discordgo.SecondaryButton,
+					CustomID: "wrapped_previous",
+					Disabled: page == 0,
+				},
+				discordgo.Button{
+					Label:    "Next",
+					Style:    discordgo.PrimaryButton,
+					CustomID: "wrapped_next",
+					Disabled: page == pageCount-1,
+				},
+				discordgo.Button{
+					Label:    "Share",
+					Style:    discordgo.SuccessButton,
+					CustomID: "wrapped_share",
+					Disabled: shared,
+				},
+			},
+		},
+	}
+}
+
+// withPageFooter returns a copy of the embed with the page number in its footer
+func withPageFooter(embed *discordgo.MessageEmbed, page, pageCount int) *discordgo.MessageEmbed {
+	e := *embed
+	e.Footer = &discordgo.MessageEmbedFooter{Text: fmt.Sprintf("Page %d of %d", page+1, pageCount)}
+	return &e
+}
+
+func (c *WrappedCommand) Handle(ctx *bot.InteractionContext) error {
+	// the report is only shown to the user until they share it
+	err := ctx.Respond(discordgo.InteractionResponseDeferredChannelMessageWithSource, &discordgo.InteractionResponseData{
+		Flags: discordgo.MessageFlagsEphemeral,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	i := ctx.Interaction()
+	s := ctx.Session()
+	user := discordutil.GetInteractionUser(i)
+
+	timezone, err := c.ts.GetTimezone(ctx.Context(), user.ID, i.GuildID)
+	if err != nil {
+		return err
+	}
+
+	year := int(discordutil.GetIntOptionOrDefault(ctx.Options(), "year", int64(carbon.Now(timezone).Year())))
+
+	report, err := c.getReport(ctx.Context(), user.ID, i.GuildID, year, timezone)
+	if err != nil {
+		return err
+	}
+
+	if report.Summary.Count == 0 {
+		_, err = ctx.Followup(&discordgo.WebhookParams{
+			Content: fmt.Sprintf("You have no activity in %d!", year),
+		}, false)
+
+		return err
+	}
+
+	card, err := c.renderer.RenderCard(
+		ctx.Context(),
+		fmt.Sprintf("Immersion Wrapped %d", year),
+		user.Username,
+		report.cardStats(),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	// kept to attach the card again when sharing
+	cardBytes := card.Bytes()
+	pages := report.pages(user)
+	page := 0
+	shared := false
+
+	msg, err := ctx.Followup(&discordgo.WebhookParams{
+		Embeds:     []*discordgo.MessageEmbed{withPageFooter(pages[page], page, len(pages))},
+		Components: wrappedComponents(page, len(pages), shared),
+		Files: []*discordgo.File{
+			{
+				Name:        "wrapped.png",
+				ContentType: "image/png",
+				Reader:      bytes.NewReader(cardBytes),
+			},
+		},
+	}, true)
+
+	if err != nil {
+		return err
+	}
+
+	collectionContext, cancel := context.WithTimeout(ctx.Context(), 5*time.Minute)
+
+	defer cancel()
+
+	interactions, err := ctx.Bot.NewMessageComponentInteractionChannel(
+		collectionContext,
+		msg,
+		discordutil.NewInteractionUserFilter(i),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	for ci := range interactions {
+		switch ci.MessageComponentData().CustomID {
+		case "wrapped_previous":
+			page = max(page-1, 0)
+		case "wrapped_next":
+			page = min(page+1, len(pages)-1)
+		case "wrapped_share":
+			shared = true
+		}
+
+		err = s.InteractionRespond(ci.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseUpdateMessage,
+			Data: &discordgo.InteractionResponseData{
+				Embeds:     []*discordgo.MessageEmbed{withPageFooter(pages[page], page, len(pages))},
+				Components: wrappedComponents(page, len(pages), shared),
+			},
+		})
+
+		if err != nil {
+			return err
+		}
+
+		if ci.MessageComponentData().CustomID != "wrapped_share" {
+			continue
+		}
+
+		// a followup of the button press is public, unlike the report
+		_, err = s.FollowupMessageCreate(ci.Interaction, false, &discordgo.WebhookParams{
+			Content: fmt.Sprintf("%s shared their %d wrapped!", user.Mention(), year),
+			Embeds:  pages,
+			Files: []*discordgo.File{
+				{
+					Name:        "wrapped.png",
+					ContentType: "image/png",
+					Reader:      bytes.NewReader(cardBytes),
+				},
+			},
+			AllowedMentions: &discordgo.MessageAllowedMentions{},
+		})
+
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = ctx.Session().InteractionResponseEdit(ctx.Interaction().Interaction, &discordgo.WebhookEdit{
+		Components: &[]discordgo.MessageComponent{},
+	})
+
+	return err
+}
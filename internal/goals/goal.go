@@ -1,13 +1,23 @@
 package goals
 
 import (
+	"fmt"
+	"regexp"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/adhocore/gronx"
 )
 
+const (
+	GoalUnitDuration   = "duration"
+	GoalUnitCharacters = "characters"
+	GoalUnitPages      = "pages"
+	GoalUnitEpisodes   = "episodes"
+)
+
 type Goal struct {
 	ID              int64
 	UserID          string
@@ -15,11 +25,29 @@ type Goal struct {
 	ActivityType    *string
 	MediaType       *string
 	YoutubeChannels []string
-	Target          time.Duration
-	Current         time.Duration
-	Cron            string
-	DueAt           time.Time
-	CreatedAt       time.Time
+	// AnidbIDs and VndbIDs match activities logged for specific works,
+	// an activity matching either list passes the filter
+	AnidbIDs []string
+	VndbIDs  []string
+	// Tags match activities with any of the given AniDB tags
+	Tags []string
+	// NamePattern matches activities whose name contains the pattern,
+	// where * matches any text (case-insensitive)
+	NamePattern *string
+	Unit        string
+	// Target and Current are measured in Unit,
+	// nanoseconds for duration goals
+	Target  int64
+	Current int64
+	Cron    string
+	DueAt   time.Time
+	// Reminder is how long before DueAt the user is sent a
+	// reminder if the goal is not completed, 0 to disable
+	Reminder time.Duration
+	// PausedAt is set while the goal is paused, paused
+	// goals are not updated and do not roll over
+	PausedAt  *time.Time
+	CreatedAt time.Time
 }
 
 func (g *Goal) MatchesActivity(a *activities.Activity) bool {
@@ -31,27 +59,193 @@ func (g *Goal) MatchesActivity(a *activities.Activity) bool {
 		return false
 	}
 
+	if !g.matchesWork(a) || !g.matchesTags(a) || !g.matchesNamePattern(a) {
+		return false
+	}
+
 	if len(g.YoutubeChannels) == 0 {
 		return true
 	}
 
-	meta, ok := a.Meta.(*activities.VideoInfo)
+	channelHandle, ok := a.GetMetaString("channel_handle")
 
 	if !ok {
 		return false
 	}
 
-	if len(g.YoutubeChannels) > 0 && !slices.Contains(g.YoutubeChannels, meta.ChannelHandle) {
+	if len(g.YoutubeChannels) > 0 && !slices.Contains(g.YoutubeChannels, channelHandle) {
 		return false
 	}
 
 	return true
 }
 
+func (g *Goal) matchesWork(a *activities.Activity) bool {
+	if len(g.AnidbIDs) == 0 && len(g.VndbIDs) == 0 {
+		return true
+	}
+
+	if id, ok := a.GetMetaString("anidb_id"); ok && slices.Contains(g.AnidbIDs, id) {
+		return true
+	}
+
+	if id, ok := a.GetMetaString("vndb_id"); ok && slices.Contains(g.VndbIDs, id) {
+		return true
+	}
+
+	return false
+}
+
+func (g *Goal) matchesTags(a *activities.Activity) bool {
+	if len(g.Tags) == 0 {
+		return true
+	}
+
+	tags, _ := a.GetMetaStrings("tags")
+
+	for _, tag := range tags {
+		if slices.ContainsFunc(g.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (g *Goal) matchesNamePattern(a *activities.Activity) bool {
+	if g.NamePattern == nil {
+		return true
+	}
+
+	return NamePatternRegexp(*g.NamePattern).MatchString(a.Name)
+}
+
+// NamePatternRegexp converts a goal's name pattern into the
+// equivalent case-insensitive, unanchored regular expression
+func NamePatternRegexp(pattern string) *regexp.Regexp {
+	quoted := regexp.QuoteMeta(pattern)
+	quoted = strings.ReplaceAll(quoted, `\*`, ".*")
+	return regexp.MustCompile("(?i)" + quoted)
+}
+
+// ActivityValue returns how much the activity contributes towards the goal in the goal's unit
+func (g *Goal) ActivityValue(a *activities.Activity) int64 {
+	switch g.Unit {
+	case GoalUnitCharacters, GoalUnitPages, GoalUnitEpisodes:
+		// unit names match the meta keys set when logging
+		value, _ := a.GetMetaFloat(g.Unit)
+		return int64(value)
+	default:
+		return int64(a.Duration)
+	}
+}
+
+// FormatValue formats an amount of the goal's unit, such as its target or current progress
+func (g *Goal) FormatValue(value int64) string {
+	switch g.Unit {
+	case GoalUnitCharacters, GoalUnitPages, GoalUnitEpisodes:
+		return fmt.Sprintf("%d %s", value, g.Unit)
+	default:
+		return time.Duration(value).String()
+	}
+}
+
+func (g *Goal) IsCompleted() bool {
+	return g.Current >= g.Target
+}
+
+// Percent returns the progress towards the target as a percentage
+func (g *Goal) Percent() float64 {
+	if g.Target == 0 {
+		return 100
+	}
+
+	return float64(g.Current) / float64(g.Target) * 100
+}
+
+// PeriodStart returns the start of the goal's current period
+// (the cron tick preceding DueAt) in the given location
+func (g *Goal) PeriodStart(location *time.Location) (time.Time, error) {
+	return gronx.PrevTickBefore(g.Cron, g.DueAt.In(location), false)
+}
+
+// InPeriod reports whether the activity's date falls within
+// the goal's current period, which starts at periodStart
+func (g *Goal) InPeriod(a *activities.Activity, periodStart time.Time) bool {
+	return !a.Date.Before(periodStart) && a.Date.Before(g.DueAt)
+}
+
+// Counts reports whether the activity should count towards the goal's current progress
+func (g *Goal) Counts(a *activities.Activity, periodStart time.Time) bool {
+	return g.MatchesActivity(a) && g.InPeriod(a, periodStart)
+}
+
+// CountsSameAs reports whether the goal's progress is counted the same way as
+// other's, that is whether they have the same filters, unit and cron
+func (g *Goal) CountsSameAs(other *Goal) bool {
+	return equalOptional(g.ActivityType, other.ActivityType) &&
+		equalOptional(g.MediaType, other.MediaType) &&
+		slices.Equal(g.YoutubeChannels, other.YoutubeChannels) &&
+		slices.Equal(g.AnidbIDs, other.AnidbIDs) &&
+		slices.Equal(g.VndbIDs, other.VndbIDs) &&
+		slices.Equal(g.Tags, other.Tags) &&
+		equalOptional(g.NamePattern, other.NamePattern) &&
+		g.Unit == other.Unit &&
+		g.Cron == other.Cron
+}
+
+func equalOptional(a, b *string) bool {
+	return a == nil && b == nil || a != nil && b != nil && *a == *b
+}
+
+func (g *Goal) IsPaused() bool {
+	return g.PausedAt != nil
+}
+
+// Pause stops the goal from being updated until Resume is called
+func (g *Goal) Pause(now time.Time) {
+	if !g.IsPaused() {
+		g.PausedAt = &now
+	}
+}
+
+// Resume unpauses the goal, moving it into the period containing now
+// without recording the periods that passed while it was paused
+func (g *Goal) Resume(now time.Time) (err error) {
+	if !g.IsPaused() {
+		return
+	}
+
+	g.PausedAt = nil
+
+	if g.IsDue(now) {
+		g.DueAt, err = g.NextDueTime(now)
+		g.Current = 0
+	}
+
+	return
+}
+
+// Reschedule sets DueAt to the next tick of the goal's cron after now,
+// used after the cron has been changed
+func (g *Goal) Reschedule(now time.Time) (err error) {
+	g.DueAt, err = gronx.NextTickAfter(g.Cron, now, true)
+	return
+}
+
 func (g *Goal) IsDue(now time.Time) bool {
 	return g.DueAt.Before(now)
 }
 
+// NeedsReminder reports whether the goal's reminder should be sent at now
+func (g *Goal) NeedsReminder(now time.Time) bool {
+	if g.Reminder <= 0 || g.IsPaused() || g.IsCompleted() || g.IsDue(now) {
+		return false
+	}
+
+	return !now.Before(g.DueAt.Add(-g.Reminder))
+}
+
 func (g *Goal) NextDueTime(now time.Time) (t time.Time, err error) {
 	if !g.IsDue(now) {
 		return g.DueAt, nil
@@ -2,19 +2,24 @@ package goals
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/UTD-JLA/botsu/internal/activities"
 	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/jackc/pgx/v5"
 )
 
+var ErrGoalNotFound = errors.New("goal not found")
+
 type GoalService struct {
 	*GoalRepository
+	ar *activities.ActivityRepository
 	ts *users.UserTimeService
 }
 
-func NewGoalService(repo *GoalRepository, ts *users.UserTimeService) *GoalService {
-	return &GoalService{repo, ts}
+func NewGoalService(repo *GoalRepository, ar *activities.ActivityRepository, ts *users.UserTimeService) *GoalService {
+	return &GoalService{repo, ar, ts}
 }
 
 func (s *GoalService) NextCron(ctx context.Context, g *Goal) (t time.Time, err error) {
@@ -40,11 +45,13 @@ func (s *GoalService) PreviousCron(ctx context.Context, g *Goal) (t time.Time, e
 }
 
 func (s *GoalService) CheckCompleted(ctx context.Context, a *activities.Activity) (completed []*Goal, err error) {
-	now, err := s.ts.GetTime(ctx, a.UserID, "")
+	location, err := s.ts.GetTimeLocation(ctx, a.UserID, "")
 	if err != nil {
 		return
 	}
 
+	now := time.Now().In(location)
+
 	goals, tx, err := s.BeginUpdateTxByUserID(ctx, a.UserID)
 	if err != nil {
 		return
@@ -53,22 +60,92 @@ func (s *GoalService) CheckCompleted(ctx context.Context, a *activities.Activity
 	defer tx.Rollback(ctx) //nolint:errcheck
 
 	for _, g := range goals {
+		if g.IsPaused() {
+			continue
+		}
+
 		changed := false
 		if g.IsDue(now) {
-			g.DueAt, err = g.NextDueTime(now)
+			if err = s.rollOverTx(ctx, tx, g, now, location); err != nil {
+				return
+			}
+			changed = true
+		}
+
+		var periodStart time.Time
+		if periodStart, err = g.PeriodStart(location); err != nil {
+			return
+		}
+
+		alreadyCompleted := g.IsCompleted()
+		if g.Counts(a, periodStart) {
+			g.Current += g.ActivityValue(a)
+			changed = true
+		}
+		if g.IsCompleted() && !alreadyCompleted {
+			completed = append(completed, g)
+		}
+
+		if changed {
+			err = s.UpdateTx(ctx, tx, g)
 			if err != nil {
 				return
 			}
-			g.Current = 0
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
+
+// CheckEdited updates the progress of the user's goals after an activity has been
+// changed from before to after, returning the goals that became completed by the change
+func (s *GoalService) CheckEdited(ctx context.Context, before, after *activities.Activity) (completed []*Goal, err error) {
+	location, err := s.ts.GetTimeLocation(ctx, after.UserID, "")
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByUserID(ctx, after.UserID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, g := range goals {
+		if g.IsPaused() {
+			continue
+		}
+
+		changed := false
+		if g.IsDue(now) {
+			if err = s.rollOverTx(ctx, tx, g, now, location); err != nil {
+				return
+			}
 			changed = true
 		}
 
-		alreadyCompleted := g.Current >= g.Target
-		if g.MatchesActivity(a) {
-			g.Current += a.Duration
+		var periodStart time.Time
+		if periodStart, err = g.PeriodStart(location); err != nil {
+			return
+		}
+
+		alreadyCompleted := g.IsCompleted()
+
+		if g.Counts(before, periodStart) {
+			g.Current = max(g.Current-g.ActivityValue(before), 0)
 			changed = true
 		}
-		if g.Current >= g.Target && !alreadyCompleted {
+
+		if g.Counts(after, periodStart) {
+			g.Current += g.ActivityValue(after)
+			changed = true
+		}
+
+		if g.IsCompleted() && !alreadyCompleted {
 			completed = append(completed, g)
 		}
 
@@ -84,12 +161,118 @@ func (s *GoalService) CheckCompleted(ctx context.Context, a *activities.Activity
 	return
 }
 
+// Calculate sets the goal's progress to the total of all
+// matching activities logged within its current period
+func (s *GoalService) Calculate(ctx context.Context, g *Goal) error {
+	location, err := s.ts.GetTimeLocation(ctx, g.UserID, "")
+	if err != nil {
+		return err
+	}
+
+	periodStart, err := g.PeriodStart(location)
+	if err != nil {
+		return err
+	}
+
+	as, err := s.ar.GetByUserIDBetween(ctx, g.UserID, periodStart, g.DueAt)
+	if err != nil {
+		return err
+	}
+
+	g.Current = 0
+
+	for _, a := range as {
+		if g.MatchesActivity(a) {
+			g.Current += g.ActivityValue(a)
+		}
+	}
+
+	return nil
+}
+
+// Recalculate rebuilds the progress of all of the user's goals from their activities,
+// used after activities have been removed (see CheckCompleted for newly logged activities)
+func (s *GoalService) Recalculate(ctx context.Context, userID string) (goals []*Goal, err error) {
+	location, err := s.ts.GetTimeLocation(ctx, userID, "")
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByUserID(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	// paused goals keep their progress until they are resumed
+	active := make([]*Goal, 0, len(goals))
+	for _, g := range goals {
+		if !g.IsPaused() {
+			active = append(active, g)
+		}
+	}
+
+	if len(active) == 0 {
+		return
+	}
+
+	periodStarts := make([]time.Time, len(active))
+	var start, end time.Time
+
+	for i, g := range active {
+		if err = s.rollOverTx(ctx, tx, g, now, location); err != nil {
+			return
+		}
+
+		if periodStarts[i], err = g.PeriodStart(location); err != nil {
+			return
+		}
+
+		if i == 0 || periodStarts[i].Before(start) {
+			start = periodStarts[i]
+		}
+
+		if i == 0 || g.DueAt.After(end) {
+			end = g.DueAt
+		}
+	}
+
+	// fetch the activities of all periods at once, then
+	// filter them per goal
+	as, err := s.ar.GetByUserIDBetween(ctx, userID, start, end)
+	if err != nil {
+		return
+	}
+
+	for i, g := range active {
+		g.Current = 0
+
+		for _, a := range as {
+			if g.Counts(a, periodStarts[i]) {
+				g.Current += g.ActivityValue(a)
+			}
+		}
+
+		if err = s.UpdateTx(ctx, tx, g); err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
+
 func (s *GoalService) CheckAll(ctx context.Context, userID string) (goals []*Goal, err error) {
-	now, err := s.ts.GetTime(ctx, userID, "")
+	location, err := s.ts.GetTimeLocation(ctx, userID, "")
 	if err != nil {
 		return
 	}
 
+	now := time.Now().In(location)
+
 	goals, tx, err := s.BeginUpdateTxByUserID(ctx, userID)
 	if err != nil {
 		return
@@ -98,12 +281,10 @@ func (s *GoalService) CheckAll(ctx context.Context, userID string) (goals []*Goa
 	defer tx.Rollback(ctx) //nolint:errcheck
 
 	for _, g := range goals {
-		if g.IsDue(now) {
-			g.DueAt, err = g.NextDueTime(now)
-			if err != nil {
+		if !g.IsPaused() && g.IsDue(now) {
+			if err = s.rollOverTx(ctx, tx, g, now, location); err != nil {
 				return
 			}
-			g.Current = 0
 			err = s.UpdateTx(ctx, tx, g)
 			if err != nil {
 				return
@@ -114,3 +295,94 @@ func (s *GoalService) CheckAll(ctx context.Context, userID string) (goals []*Goa
 	err = tx.Commit(ctx)
 	return
 }
+
+// Modify applies modify to one of the user's goals and saves it, rebuilding the
+// goal's progress afterwards. Returns ErrGoalNotFound if the user has no such goal.
+func (s *GoalService) Modify(ctx context.Context, userID string, id int64, modify func(g *Goal, now time.Time) error) (goal *Goal, err error) {
+	location, err := s.ts.GetTimeLocation(ctx, userID, "")
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByUserID(ctx, userID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, g := range goals {
+		if g.ID == id {
+			goal = g
+			break
+		}
+	}
+
+	if goal == nil {
+		err = ErrGoalNotFound
+		return
+	}
+
+	// finish the previous period before the goal is changed
+	if !goal.IsPaused() && goal.IsDue(now) {
+		if err = s.rollOverTx(ctx, tx, goal, now, location); err != nil {
+			return
+		}
+	}
+
+	before := *goal
+
+	if err = modify(goal, now); err != nil {
+		return
+	}
+
+	// the progress only has to be counted again if what counts towards it changed,
+	// resuming keeps the progress made before the goal was paused
+	if !goal.IsPaused() && !goal.CountsSameAs(&before) {
+		if err = s.Calculate(ctx, goal); err != nil {
+			return
+		}
+	}
+
+	if err = s.UpdateTx(ctx, tx, goal); err != nil {
+		return
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
+
+// PendingReminders returns the goals whose reminder should be sent at now
+func (s *GoalService) PendingReminders(ctx context.Context, now time.Time) (pending []*Goal, err error) {
+	goals, err := s.FindPendingReminders(ctx, now)
+	if err != nil {
+		return
+	}
+
+	for _, g := range goals {
+		if g.NeedsReminder(now) {
+			pending = append(pending, g)
+		}
+	}
+
+	return
+}
+
+// rollOverTx moves a due goal into its current period and
+// records the periods that were finished (see Goal.RollOver)
+func (s *GoalService) rollOverTx(ctx context.Context, tx pgx.Tx, g *Goal, now time.Time, location *time.Location) error {
+	finished, err := g.RollOver(now, location)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range finished {
+		if err = s.CreatePeriodTx(ctx, tx, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,230 @@
+package goals_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/pkg/ref"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoalCounts(t *testing.T) {
+	location := time.UTC
+	g := &goals.Goal{
+		Cron:      "@weekly",
+		MediaType: ref.New(activities.ActivityMediaTypeAnime),
+		// Sunday
+		DueAt: time.Date(2024, 1, 7, 0, 0, 0, 0, location),
+	}
+
+	periodStart, err := g.PeriodStart(location)
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2023, 12, 31, 0, 0, 0, 0, location), periodStart)
+
+	a := activities.NewActivity()
+	a.PrimaryType = activities.ActivityImmersionTypeListening
+	a.MediaType = ref.New(activities.ActivityMediaTypeAnime)
+	a.Date = time.Date(2024, 1, 3, 12, 0, 0, 0, location)
+	assert.True(t, g.Counts(a, periodStart))
+
+	a.Date = time.Date(2023, 12, 30, 12, 0, 0, 0, location)
+	assert.False(t, g.Counts(a, periodStart))
+
+	a.Date = time.Date(2024, 1, 3, 12, 0, 0, 0, location)
+	a.MediaType = ref.New(activities.ActivityMediaTypeVideo)
+	assert.False(t, g.Counts(a, periodStart))
+}
+
+func TestGoalMatchesYoutubeChannel(t *testing.T) {
+	g := &goals.Goal{YoutubeChannels: []string{"@HakuiKoyori"}}
+
+	a := activities.NewActivity()
+	a.Meta = &activities.VideoInfo{ChannelHandle: "@HakuiKoyori"}
+	assert.True(t, g.MatchesActivity(a))
+
+	// meta as read from the database
+	a.Meta = map[string]interface{}{"channel_handle": "@ui_shig"}
+	assert.False(t, g.MatchesActivity(a))
+}
+
+func TestGoalMatchesWork(t *testing.T) {
+	g := &goals.Goal{AnidbIDs: []string{"17709"}}
+
+	a := activities.NewActivity()
+	a.Name = "Bocchi the Rock! Episode 3"
+	a.Meta = map[string]interface{}{"anidb_id": "17709", "tags": []interface{}{"Comedy", "Music"}}
+	assert.True(t, g.MatchesActivity(a))
+
+	g.AnidbIDs = nil
+	g.VndbIDs = []string{"v17"}
+	assert.False(t, g.MatchesActivity(a))
+
+	g.VndbIDs = nil
+	g.Tags = []string{"music"}
+	assert.True(t, g.MatchesActivity(a))
+
+	g.Tags = []string{"horror"}
+	assert.False(t, g.MatchesActivity(a))
+
+	g.Tags = nil
+	g.NamePattern = ref.New("bocchi*episode")
+	assert.True(t, g.MatchesActivity(a))
+
+	g.NamePattern = ref.New("bocchi (")
+	assert.False(t, g.MatchesActivity(a))
+}
+
+func TestGoalActivityValue(t *testing.T) {
+	a := activities.NewActivity()
+	a.Duration = 90 * time.Minute
+	a.SetMeta("characters", uint(12000))
+
+	g := &goals.Goal{Unit: goals.GoalUnitCharacters, Target: 24000}
+	assert.Equal(t, int64(12000), g.ActivityValue(a))
+	assert.Equal(t, "24000 characters", g.FormatValue(g.Target))
+
+	g.Unit = goals.GoalUnitPages
+	assert.Equal(t, int64(0), g.ActivityValue(a))
+
+	g.Unit = goals.GoalUnitDuration
+	assert.Equal(t, int64(90*time.Minute), g.ActivityValue(a))
+	assert.Equal(t, "1h30m0s", g.FormatValue(g.ActivityValue(a)))
+}
+
+func TestGoalRollOver(t *testing.T) {
+	location := time.UTC
+	g := &goals.Goal{
+		ID:      1,
+		Cron:    "@daily",
+		Target:  10,
+		Current: 12,
+		DueAt:   time.Date(2024, 1, 2, 0, 0, 0, 0, location),
+	}
+
+	finished, err := g.RollOver(time.Date(2024, 1, 1, 12, 0, 0, 0, location), location)
+	assert.Nil(t, err)
+	assert.Empty(t, finished)
+	assert.Equal(t, int64(12), g.Current)
+
+	// two days were skipped without the goal being checked
+	finished, err = g.RollOver(time.Date(2024, 1, 4, 12, 0, 0, 0, location), location)
+	assert.Nil(t, err)
+	assert.Len(t, finished, 3)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, location), finished[0].PeriodStart)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, location), finished[0].PeriodEnd)
+	assert.Equal(t, int64(12), finished[0].Achieved)
+	assert.True(t, finished[0].Met)
+
+	assert.Equal(t, time.Date(2024, 1, 3, 0, 0, 0, 0, location), finished[1].PeriodEnd)
+	assert.Equal(t, time.Date(2024, 1, 4, 0, 0, 0, 0, location), finished[2].PeriodEnd)
+	assert.False(t, finished[2].Met)
+
+	assert.Equal(t, time.Date(2024, 1, 5, 0, 0, 0, 0, location), g.DueAt)
+	assert.Equal(t, int64(0), g.Current)
+}
+
+func TestCalculateStreaks(t *testing.T) {
+	periods := []*goals.GoalPeriod{
+		{Met: true},
+		{Met: true},
+		{Met: true},
+		{Met: false},
+		{Met: true},
+	}
+
+	s := goals.CalculateStreaks(periods)
+	assert.Equal(t, 1, s.Current)
+	assert.Equal(t, 3, s.Longest)
+	assert.Equal(t, 80.0, s.HitRate())
+
+	s = goals.CalculateStreaks(nil)
+	assert.Equal(t, 0, s.Longest)
+	assert.Equal(t, 0.0, s.HitRate())
+}
+
+func TestGoalNeedsReminder(t *testing.T) {
+	dueAt := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	g := &goals.Goal{
+		Target:   10,
+		Current:  5,
+		DueAt:    dueAt,
+		Reminder: 2 * time.Hour,
+	}
+
+	assert.False(t, g.NeedsReminder(dueAt.Add(-3*time.Hour)))
+	assert.True(t, g.NeedsReminder(dueAt.Add(-2*time.Hour)))
+	assert.True(t, g.NeedsReminder(dueAt.Add(-time.Minute)))
+	assert.False(t, g.NeedsReminder(dueAt.Add(time.Minute)))
+
+	g.Current = 10
+	assert.False(t, g.NeedsReminder(dueAt.Add(-time.Hour)))
+
+	g.Current = 5
+	g.Reminder = 0
+	assert.False(t, g.NeedsReminder(dueAt.Add(-time.Hour)))
+}
+
+func TestGoalPauseResume(t *testing.T) {
+	location := time.UTC
+	g := &goals.Goal{
+		Cron:    "@daily",
+		Target:  10,
+		Current: 5,
+		DueAt:   time.Date(2024, 1, 2, 0, 0, 0, 0, location),
+	}
+
+	g.Pause(time.Date(2024, 1, 1, 12, 0, 0, 0, location))
+	assert.True(t, g.IsPaused())
+
+	// resuming in the same period keeps the progress
+	assert.Nil(t, g.Resume(time.Date(2024, 1, 1, 18, 0, 0, 0, location)))
+	assert.False(t, g.IsPaused())
+	assert.Equal(t, int64(5), g.Current)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, location), g.DueAt)
+
+	g.Pause(time.Date(2024, 1, 1, 20, 0, 0, 0, location))
+	assert.Nil(t, g.Resume(time.Date(2024, 1, 5, 12, 0, 0, 0, location)))
+	assert.Equal(t, int64(0), g.Current)
+	assert.Equal(t, time.Date(2024, 1, 6, 0, 0, 0, 0, location), g.DueAt)
+}
+
+func TestGoalReschedule(t *testing.T) {
+	location := time.UTC
+	g := &goals.Goal{
+		Cron:  "@monthly",
+		DueAt: time.Date(2024, 2, 1, 0, 0, 0, 0, location),
+	}
+
+	g.Cron = "@daily"
+	assert.Nil(t, g.Reschedule(time.Date(2024, 1, 10, 12, 0, 0, 0, location)))
+	assert.Equal(t, time.Date(2024, 1, 11, 0, 0, 0, 0, location), g.DueAt)
+}
+
+func TestGoalCountsSameAs(t *testing.T) {
+	g := &goals.Goal{
+		MediaType: ref.New("anime"),
+		Tags:      []string{"romance"},
+		Unit:      goals.GoalUnitDuration,
+		Cron:      "@daily",
+	}
+
+	other := *g
+	other.Name = "Renamed"
+	other.Target = 100
+	other.MediaType = ref.New("anime")
+	assert.True(t, g.CountsSameAs(&other))
+
+	other.Tags = nil
+	assert.False(t, g.CountsSameAs(&other))
+
+	other = *g
+	other.MediaType = nil
+	assert.False(t, g.CountsSameAs(&other))
+
+	other = *g
+	other.Cron = "@weekly"
+	assert.False(t, g.CountsSameAs(&other))
+}
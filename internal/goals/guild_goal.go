@@ -0,0 +1,17 @@
+package goals
+
+// GuildGoal is a goal shared by all members of a guild, every matching
+// activity logged in the guild counts towards it. The embedded Goal has no UserID.
+type GuildGoal struct {
+	Goal
+	GuildID string
+	// ChannelID is the channel the goal's completion is announced in
+	ChannelID string
+	CreatedBy string
+}
+
+// GuildGoalContribution is the amount a member contributed to a guild goal in one period
+type GuildGoalContribution struct {
+	UserID string
+	Amount int64
+}
@@ -0,0 +1,184 @@
+package goals
+
+import (
+	"context"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/users"
+)
+
+// GuildGoalService keeps the progress of guild goals up to date,
+// guild goals use the guild's timezone for their periods
+type GuildGoalService struct {
+	*GuildGoalRepository
+	ar *activities.ActivityRepository
+	ts *users.UserTimeService
+}
+
+func NewGuildGoalService(repo *GuildGoalRepository, ar *activities.ActivityRepository, ts *users.UserTimeService) *GuildGoalService {
+	return &GuildGoalService{repo, ar, ts}
+}
+
+func (s *GuildGoalService) location(ctx context.Context, guildID string) (*time.Location, error) {
+	return s.ts.GetTimeLocation(ctx, "", guildID)
+}
+
+func (s *GuildGoalService) NextCron(ctx context.Context, g *GuildGoal) (t time.Time, err error) {
+	location, err := s.location(ctx, g.GuildID)
+	if err != nil {
+		return
+	}
+
+	return g.NextDueTime(time.Now().In(location))
+}
+
+// CheckCompleted adds the activity to the goals of the guild it was logged in,
+// returning the goals that became completed by it
+func (s *GuildGoalService) CheckCompleted(ctx context.Context, a *activities.Activity) (completed []*GuildGoal, err error) {
+	if a.GuildID == nil {
+		return
+	}
+
+	location, err := s.location(ctx, *a.GuildID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByGuildID(ctx, *a.GuildID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, g := range goals {
+		changed := false
+		if g.IsDue(now) {
+			// guild goals do not keep a history of their periods
+			if _, err = g.RollOver(now, location); err != nil {
+				return
+			}
+			changed = true
+		}
+
+		var periodStart time.Time
+		if periodStart, err = g.PeriodStart(location); err != nil {
+			return
+		}
+
+		alreadyCompleted := g.IsCompleted()
+		if g.Counts(a, periodStart) {
+			value := g.ActivityValue(a)
+			g.Current += value
+			changed = true
+
+			if err = s.AddContributionTx(ctx, tx, g.ID, g.DueAt, a.UserID, value); err != nil {
+				return
+			}
+		}
+
+		if g.IsCompleted() && !alreadyCompleted {
+			completed = append(completed, g)
+		}
+
+		if changed {
+			if err = s.UpdateTx(ctx, tx, g); err != nil {
+				return
+			}
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
+
+// Recalculate rebuilds the progress and contributions of the guild's goals from the
+// activities logged in the guild, used after activities have been edited or removed
+func (s *GuildGoalService) Recalculate(ctx context.Context, guildID string) (goals []*GuildGoal, err error) {
+	location, err := s.location(ctx, guildID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByGuildID(ctx, guildID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, g := range goals {
+		if _, err = g.RollOver(now, location); err != nil {
+			return
+		}
+
+		var periodStart time.Time
+		if periodStart, err = g.PeriodStart(location); err != nil {
+			return
+		}
+
+		var as []*activities.Activity
+		if as, err = s.ar.GetByGuildIDBetween(ctx, guildID, periodStart, g.DueAt); err != nil {
+			return
+		}
+
+		g.Current = 0
+		contributions := make(map[string]int64)
+
+		for _, a := range as {
+			if g.MatchesActivity(a) {
+				value := g.ActivityValue(a)
+				g.Current += value
+				contributions[a.UserID] += value
+			}
+		}
+
+		if err = s.ReplaceContributionsTx(ctx, tx, g.ID, g.DueAt, contributions); err != nil {
+			return
+		}
+
+		if err = s.UpdateTx(ctx, tx, g); err != nil {
+			return
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
+
+// CheckAll moves all of the guild's due goals into their current period
+func (s *GuildGoalService) CheckAll(ctx context.Context, guildID string) (goals []*GuildGoal, err error) {
+	location, err := s.location(ctx, guildID)
+	if err != nil {
+		return
+	}
+
+	now := time.Now().In(location)
+
+	goals, tx, err := s.BeginUpdateTxByGuildID(ctx, guildID)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	for _, g := range goals {
+		if g.IsDue(now) {
+			if _, err = g.RollOver(now, location); err != nil {
+				return
+			}
+
+			if err = s.UpdateTx(ctx, tx, g); err != nil {
+				return
+			}
+		}
+	}
+
+	err = tx.Commit(ctx)
+	return
+}
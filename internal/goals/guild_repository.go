@@ -0,0 +1,285 @@
+package goals
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const guildGoalColumns = `id, guild_id, channel_id, created_by, name, activity_type, media_type, youtube_channels, anidb_ids, vndb_ids, tags, name_pattern, unit, target, current, cron, due_at, created_at`
+
+type GuildGoalRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGuildGoalRepository(pool *pgxpool.Pool) *GuildGoalRepository {
+	return &GuildGoalRepository{pool}
+}
+
+func (r *GuildGoalRepository) Create(ctx context.Context, g *GuildGoal) (err error) {
+	err = r.pool.QueryRow(
+		ctx,
+		`INSERT INTO guild_goals (guild_id, channel_id, created_by, name, activity_type, media_type, youtube_channels, anidb_ids, vndb_ids, tags, name_pattern, unit, target, current, cron, due_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
+		RETURNING id`,
+		g.GuildID,
+		g.ChannelID,
+		g.CreatedBy,
+		g.Name,
+		g.ActivityType,
+		g.MediaType,
+		g.YoutubeChannels,
+		g.AnidbIDs,
+		g.VndbIDs,
+		g.Tags,
+		g.NamePattern,
+		g.Unit,
+		g.Target,
+		g.Current,
+		g.Cron,
+		g.DueAt,
+	).Scan(&g.ID)
+
+	return
+}
+
+func (r *GuildGoalRepository) FindByID(ctx context.Context, id int64) (goal *GuildGoal, err error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT `+guildGoalColumns+`
+		FROM guild_goals
+		WHERE deleted_at IS NULL
+		AND id = $1
+	`, id)
+
+	return scanGuildGoal(row)
+}
+
+// BeginUpdateTxByGuildID locks and returns the guild's goals
+func (r *GuildGoalRepository) BeginUpdateTxByGuildID(ctx context.Context, guildID string) (goals []*GuildGoal, tx pgx.Tx, err error) {
+	tx, err = r.pool.Begin(ctx)
+
+	if err != nil {
+		return
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT `+guildGoalColumns+`
+		FROM guild_goals
+		WHERE guild_id = $1
+		AND deleted_at IS NULL
+		ORDER BY id ASC
+		FOR UPDATE`,
+		guildID,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var g *GuildGoal
+		if g, err = scanGuildGoal(rows); err != nil {
+			return
+		}
+
+		goals = append(goals, g)
+	}
+
+	return
+}
+
+func (r *GuildGoalRepository) UpdateTx(ctx context.Context, tx pgx.Tx, g *GuildGoal) (err error) {
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE guild_goals
+		SET channel_id = $1, name = $2, activity_type = $3, media_type = $4, youtube_channels = $5, anidb_ids = $6, vndb_ids = $7, tags = $8, name_pattern = $9,
+			unit = $10, target = $11, current = $12, cron = $13, due_at = $14
+		WHERE id = $15`,
+		g.ChannelID,
+		g.Name,
+		g.ActivityType,
+		g.MediaType,
+		g.YoutubeChannels,
+		g.AnidbIDs,
+		g.VndbIDs,
+		g.Tags,
+		g.NamePattern,
+		g.Unit,
+		g.Target,
+		g.Current,
+		g.Cron,
+		g.DueAt,
+		g.ID,
+	)
+
+	return
+}
+
+func (r *GuildGoalRepository) DeleteByID(ctx context.Context, id int64) (err error) {
+	_, err = r.pool.Exec(ctx, "UPDATE guild_goals SET deleted_at = (NOW() AT TIME ZONE 'UTC') WHERE id = $1", id)
+	return
+}
+
+// AddContributionTx adds amount to the member's contribution to the goal's period ending at dueAt
+func (r *GuildGoalRepository) AddContributionTx(ctx context.Context, tx pgx.Tx, goalID int64, dueAt time.Time, userID string, amount int64) (err error) {
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO guild_goal_contributions (guild_goal_id, due_at, user_id, amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (guild_goal_id, due_at, user_id) DO UPDATE
+		SET amount = guild_goal_contributions.amount + $4`,
+		goalID,
+		dueAt,
+		userID,
+		amount,
+	)
+
+	return
+}
+
+// ReplaceContributionsTx replaces all contributions to the goal's period ending at dueAt
+func (r *GuildGoalRepository) ReplaceContributionsTx(ctx context.Context, tx pgx.Tx, goalID int64, dueAt time.Time, contributions map[string]int64) (err error) {
+	_, err = tx.Exec(
+		ctx,
+		`DELETE FROM guild_goal_contributions WHERE guild_goal_id = $1 AND due_at = $2`,
+		goalID,
+		dueAt,
+	)
+
+	if err != nil {
+		return
+	}
+
+	for userID, amount := range contributions {
+		if err = r.AddContributionTx(ctx, tx, goalID, dueAt, userID, amount); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// FindTopContributions returns the largest contributions to the goal's period ending at dueAt,
+// leaving out those of users with private activity
+func (r *GuildGoalRepository) FindTopContributions(ctx context.Context, goalID int64, dueAt time.Time, limit int) (contributions []*GuildGoalContribution, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT user_id, amount
+		FROM guild_goal_contributions c
+		WHERE guild_goal_id = $1
+		AND due_at = $2
+		AND amount > 0
+		AND NOT EXISTS (SELECT 1 FROM users u WHERE u.id = c.user_id AND u.privacy = 'private')
+		ORDER BY amount DESC
+		LIMIT $3`,
+		goalID,
+		dueAt,
+		limit,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		c := &GuildGoalContribution{}
+		if err = rows.Scan(&c.UserID, &c.Amount); err != nil {
+			return
+		}
+
+		contributions = append(contributions, c)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// FindPendingAnnouncements returns the completed goals whose
+// completion has not been announced for their current period
+func (r *GuildGoalRepository) FindPendingAnnouncements(ctx context.Context) (goals []*GuildGoal, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT `+guildGoalColumns+`
+		FROM guild_goals
+		WHERE deleted_at IS NULL
+		AND current >= target
+		AND announced_due_at IS DISTINCT FROM due_at`,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var g *GuildGoal
+		if g, err = scanGuildGoal(rows); err != nil {
+			return
+		}
+
+		goals = append(goals, g)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// MarkAnnounced records that the completion of the period ending at dueAt
+// has been announced, returning false if it had already been recorded
+func (r *GuildGoalRepository) MarkAnnounced(ctx context.Context, id int64, dueAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(
+		ctx,
+		`UPDATE guild_goals
+		SET announced_due_at = $2
+		WHERE id = $1
+		AND announced_due_at IS DISTINCT FROM $2`,
+		id,
+		dueAt,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+// scanGuildGoal scans a row selected with guildGoalColumns
+func scanGuildGoal(row pgx.Row) (*GuildGoal, error) {
+	g := &GuildGoal{}
+
+	err := row.Scan(
+		&g.ID,
+		&g.GuildID,
+		&g.ChannelID,
+		&g.CreatedBy,
+		&g.Name,
+		&g.ActivityType,
+		&g.MediaType,
+		&g.YoutubeChannels,
+		&g.AnidbIDs,
+		&g.VndbIDs,
+		&g.Tags,
+		&g.NamePattern,
+		&g.Unit,
+		&g.Target,
+		&g.Current,
+		&g.Cron,
+		&g.DueAt,
+		&g.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -0,0 +1,113 @@
+package goals
+
+import (
+	"time"
+
+	"github.com/adhocore/gronx"
+)
+
+// maximum number of empty periods recorded when a goal
+// has not been checked for more than one period
+const maxMissedPeriods = 100
+
+// GoalPeriod is the result of a finished period of a goal
+type GoalPeriod struct {
+	ID          int64
+	GoalID      int64
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Achieved    int64
+	Target      int64
+	Met         bool
+	CreatedAt   time.Time
+}
+
+// RollOver moves the goal into the period containing now if it is due,
+// resetting its progress and returning the periods that were finished,
+// including any that passed without the goal being checked
+func (g *Goal) RollOver(now time.Time, location *time.Location) (finished []*GoalPeriod, err error) {
+	if !g.IsDue(now) {
+		return
+	}
+
+	periodStart, err := g.PeriodStart(location)
+	if err != nil {
+		return
+	}
+
+	finished = append(finished, &GoalPeriod{
+		GoalID:      g.ID,
+		PeriodStart: periodStart,
+		PeriodEnd:   g.DueAt,
+		Achieved:    g.Current,
+		Target:      g.Target,
+		Met:         g.IsCompleted(),
+	})
+
+	dueAt, err := g.NextDueTime(now)
+	if err != nil {
+		return
+	}
+
+	start := g.DueAt.In(location)
+	for i := 0; i < maxMissedPeriods; i++ {
+		var end time.Time
+		if end, err = gronx.NextTickAfter(g.Cron, start, false); err != nil {
+			return
+		}
+
+		if !end.Before(dueAt) {
+			break
+		}
+
+		finished = append(finished, &GoalPeriod{
+			GoalID:      g.ID,
+			PeriodStart: start,
+			PeriodEnd:   end,
+			Target:      g.Target,
+			Met:         g.Target == 0,
+		})
+
+		start = end
+	}
+
+	g.DueAt = dueAt
+	g.Current = 0
+	return
+}
+
+// GoalStreaks summarizes the history of a goal
+type GoalStreaks struct {
+	Current int
+	Longest int
+	Met     int
+	Total   int
+}
+
+// HitRate returns the percentage of periods in which the goal was met
+func (s *GoalStreaks) HitRate() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+
+	return float64(s.Met) / float64(s.Total) * 100
+}
+
+// CalculateStreaks calculates the streaks of a goal from
+// its finished periods, which must be ordered oldest first
+func CalculateStreaks(periods []*GoalPeriod) (s GoalStreaks) {
+	for _, p := range periods {
+		s.Total++
+
+		if !p.Met {
+			s.Current = 0
+			continue
+		}
+
+		s.Met++
+		s.Current++
+		s.Longest = max(s.Longest, s.Current)
+	}
+
+	return
+}
@@ -2,11 +2,14 @@ package goals
 
 import (
 	"context"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const goalColumns = `id, user_id, name, activity_type, media_type, youtube_channels, anidb_ids, vndb_ids, tags, name_pattern, unit, target, current, cron, due_at, reminder, paused_at, created_at`
+
 type GoalRepository struct {
 	pool *pgxpool.Pool
 }
@@ -18,18 +21,25 @@ func NewGoalRepository(pool *pgxpool.Pool) *GoalRepository {
 func (r *GoalRepository) Create(ctx context.Context, g *Goal) (err error) {
 	err = r.pool.QueryRow(
 		ctx,
-		`INSERT INTO goals (user_id, name, activity_type, media_type, youtube_channels, target, current, cron, due_at, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+		`INSERT INTO goals (user_id, name, activity_type, media_type, youtube_channels, anidb_ids, vndb_ids, tags, name_pattern, unit, target, current, cron, due_at, reminder, paused_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, NOW())
 		RETURNING id`,
 		g.UserID,
 		g.Name,
 		g.ActivityType,
 		g.MediaType,
 		g.YoutubeChannels,
+		g.AnidbIDs,
+		g.VndbIDs,
+		g.Tags,
+		g.NamePattern,
+		g.Unit,
 		g.Target,
 		g.Current,
 		g.Cron,
 		g.DueAt,
+		g.Reminder,
+		g.PausedAt,
 	).Scan(&g.ID)
 
 	return
@@ -37,35 +47,19 @@ func (r *GoalRepository) Create(ctx context.Context, g *Goal) (err error) {
 
 func (r *GoalRepository) FindByID(ctx context.Context, id int64) (goal *Goal, err error) {
 	row := r.pool.QueryRow(ctx, `
-		SELECT id, user_id, name, activity_type, media_type, youtube_channels, target, current, cron, due_at, created_at
-		FROM goals		
+		SELECT `+goalColumns+`
+		FROM goals
 		WHERE deleted_at IS NULL
 		AND id = $1
 	`, id)
 
-	goal = &Goal{}
-
-	err = row.Scan(
-		&goal.ID,
-		&goal.UserID,
-		&goal.Name,
-		&goal.ActivityType,
-		&goal.MediaType,
-		&goal.YoutubeChannels,
-		&goal.Target,
-		&goal.Current,
-		&goal.Cron,
-		&goal.DueAt,
-		&goal.CreatedAt,
-	)
-
-	return
+	return scanGoal(row)
 }
 
 func (r *GoalRepository) FindByUserID(ctx context.Context, userID string) (goals []*Goal, err error) {
 	rows, err := r.pool.Query(
 		ctx,
-		`SELECT id, user_id, name, activity_type, media_type, youtube_channels, target, current, cron, due_at, created_at
+		`SELECT `+goalColumns+`
 		FROM goals
 		WHERE deleted_at IS NULL
 		AND user_id = $1`,
@@ -79,22 +73,8 @@ func (r *GoalRepository) FindByUserID(ctx context.Context, userID string) (goals
 	defer rows.Close()
 
 	for rows.Next() {
-		g := &Goal{}
-		err = rows.Scan(
-			&g.ID,
-			&g.UserID,
-			&g.Name,
-			&g.ActivityType,
-			&g.MediaType,
-			&g.YoutubeChannels,
-			&g.Target,
-			&g.Current,
-			&g.Cron,
-			&g.DueAt,
-			&g.CreatedAt,
-		)
-
-		if err != nil {
+		var g *Goal
+		if g, err = scanGoal(rows); err != nil {
 			return
 		}
 
@@ -113,7 +93,7 @@ func (r *GoalRepository) BeginUpdateTxByUserID(ctx context.Context, userID strin
 
 	rows, err := tx.Query(
 		ctx,
-		`SELECT id, user_id, name, activity_type, media_type, youtube_channels, target, current, cron, due_at, created_at
+		`SELECT `+goalColumns+`
 		FROM goals
 		WHERE user_id = $1
 		AND DELETED_AT IS NULL
@@ -128,22 +108,8 @@ func (r *GoalRepository) BeginUpdateTxByUserID(ctx context.Context, userID strin
 	defer rows.Close()
 
 	for rows.Next() {
-		g := &Goal{}
-		err = rows.Scan(
-			&g.ID,
-			&g.UserID,
-			&g.Name,
-			&g.ActivityType,
-			&g.MediaType,
-			&g.YoutubeChannels,
-			&g.Target,
-			&g.Current,
-			&g.Cron,
-			&g.DueAt,
-			&g.CreatedAt,
-		)
-
-		if err != nil {
+		var g *Goal
+		if g, err = scanGoal(rows); err != nil {
 			return
 		}
 
@@ -157,22 +123,118 @@ func (r *GoalRepository) UpdateTx(ctx context.Context, tx pgx.Tx, g *Goal) (err
 	_, err = tx.Exec(
 		ctx,
 		`UPDATE goals
-		SET name = $1, activity_type = $2, media_type = $3, youtube_channels = $4, target = $5, current = $6, cron = $7, due_at = $8
-		WHERE id = $9`,
+		SET name = $1, activity_type = $2, media_type = $3, youtube_channels = $4, anidb_ids = $5, vndb_ids = $6, tags = $7, name_pattern = $8,
+			unit = $9, target = $10, current = $11, cron = $12, due_at = $13, reminder = $14, paused_at = $15
+		WHERE id = $16`,
 		g.Name,
 		g.ActivityType,
 		g.MediaType,
 		g.YoutubeChannels,
+		g.AnidbIDs,
+		g.VndbIDs,
+		g.Tags,
+		g.NamePattern,
+		g.Unit,
 		g.Target,
 		g.Current,
 		g.Cron,
 		g.DueAt,
+		g.Reminder,
+		g.PausedAt,
 		g.ID,
 	)
 
 	return
 }
 
+// FindPendingReminders returns the uncompleted goals whose reminder is due at now
+// and has not been sent for their current period, skipping users who opted out
+func (r *GoalRepository) FindPendingReminders(ctx context.Context, now time.Time) (goals []*Goal, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT `+goalColumns+`
+		FROM goals
+		WHERE deleted_at IS NULL
+		AND reminder > 0
+		AND paused_at IS NULL
+		AND current < target
+		AND due_at > $1
+		AND due_at - (reminder / 1000) * INTERVAL '1 microsecond' <= $1
+		AND reminded_due_at IS DISTINCT FROM due_at
+		AND user_id NOT IN (SELECT id FROM users WHERE NOT goal_reminders)`,
+		now,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var g *Goal
+		if g, err = scanGoal(rows); err != nil {
+			return
+		}
+
+		goals = append(goals, g)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// FindUserIDsWithDueGoals returns the users who have unpaused goals that are due at now
+func (r *GoalRepository) FindUserIDsWithDueGoals(ctx context.Context, now time.Time) (userIDs []string, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT DISTINCT user_id
+		FROM goals
+		WHERE deleted_at IS NULL
+		AND paused_at IS NULL
+		AND due_at < $1`,
+		now,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID string
+		if err = rows.Scan(&userID); err != nil {
+			return
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// MarkReminded records that the reminder for the period ending at dueAt has been sent,
+// returning false if it had already been recorded
+func (r *GoalRepository) MarkReminded(ctx context.Context, id int64, dueAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(
+		ctx,
+		`UPDATE goals
+		SET reminded_due_at = $2
+		WHERE id = $1
+		AND reminded_due_at IS DISTINCT FROM $2`,
+		id,
+		dueAt,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *GoalRepository) DeleteByID(ctx context.Context, id int64) (err error) {
 	conn, err := r.pool.Acquire(ctx)
 
@@ -186,3 +248,115 @@ func (r *GoalRepository) DeleteByID(ctx context.Context, id int64) (err error) {
 
 	return
 }
+
+// CreatePeriodTx records a finished period of a goal, ignoring
+// periods that have already been recorded
+func (r *GoalRepository) CreatePeriodTx(ctx context.Context, tx pgx.Tx, p *GoalPeriod) (err error) {
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO goal_periods (goal_id, period_start, period_end, achieved, target, met)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (goal_id, period_end) DO NOTHING`,
+		p.GoalID,
+		p.PeriodStart,
+		p.PeriodEnd,
+		p.Achieved,
+		p.Target,
+		p.Met,
+	)
+
+	return
+}
+
+// FindPeriodsByGoalID returns the finished periods of a goal, oldest first
+func (r *GoalRepository) FindPeriodsByGoalID(ctx context.Context, goalID int64) (periods []*GoalPeriod, err error) {
+	rows, err := r.pool.Query(
+		ctx,
+		`SELECT id, goal_id, period_start, period_end, achieved, target, met, created_at
+		FROM goal_periods
+		WHERE goal_id = $1
+		ORDER BY period_end ASC`,
+		goalID,
+	)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		p := &GoalPeriod{}
+		err = rows.Scan(
+			&p.ID,
+			&p.GoalID,
+			&p.PeriodStart,
+			&p.PeriodEnd,
+			&p.Achieved,
+			&p.Target,
+			&p.Met,
+			&p.CreatedAt,
+		)
+
+		if err != nil {
+			return
+		}
+
+		periods = append(periods, p)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// CountMetPeriodsByUserID returns the number of periods of the user's goals
+// that were met and ended between start and end
+func (r *GoalRepository) CountMetPeriodsByUserID(ctx context.Context, userID string, start, end time.Time) (count int, err error) {
+	err = r.pool.QueryRow(
+		ctx,
+		`SELECT COUNT(*)
+		FROM goal_periods
+		JOIN goals ON goals.id = goal_periods.goal_id
+		WHERE goals.user_id = $1
+		AND goal_periods.met
+		AND goal_periods.period_end >= $2
+		AND goal_periods.period_end <= $3`,
+		userID,
+		start,
+		end,
+	).Scan(&count)
+
+	return
+}
+
+// scanGoal scans a row selected with goalColumns
+func scanGoal(row pgx.Row) (*Goal, error) {
+	g := &Goal{}
+
+	err := row.Scan(
+		&g.ID,
+		&g.UserID,
+		&g.Name,
+		&g.ActivityType,
+		&g.MediaType,
+		&g.YoutubeChannels,
+		&g.AnidbIDs,
+		&g.VndbIDs,
+		&g.Tags,
+		&g.NamePattern,
+		&g.Unit,
+		&g.Target,
+		&g.Current,
+		&g.Cron,
+		&g.DueAt,
+		&g.Reminder,
+		&g.PausedAt,
+		&g.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
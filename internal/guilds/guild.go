@@ -1,13 +1,57 @@
 package guilds
 
+import "time"
+
 type Guild struct {
 	ID       string
 	Timezone *string
+	// WeekStart is the first day of the week of weekly leaderboards
+	WeekStart time.Weekday
+	// the leaderboard is posted in LeaderboardChannelID at the end of
+	// each period of LeaderboardCron, next at LeaderboardDueAt
+	LeaderboardChannelID *string
+	LeaderboardCron      *string
+	LeaderboardDueAt     *time.Time
+}
+
+// MilestoneRole is given to members of the guild once they have
+// logged Hours in total, and taken away if they fall below it
+type MilestoneRole struct {
+	GuildID string
+	RoleID  string
+	Hours   int
+}
+
+// MilestoneRoleChanges returns the milestone roles a member who has logged total
+// in all and has the roles memberRoles must be given and must have taken away
+func MilestoneRoleChanges(roles []*MilestoneRole, total time.Duration, memberRoles []string) (add, remove []string) {
+	has := make(map[string]bool, len(memberRoles))
+	for _, roleID := range memberRoles {
+		has[roleID] = true
+	}
+
+	for _, role := range roles {
+		reached := total >= time.Duration(role.Hours)*time.Hour
+
+		if reached && !has[role.RoleID] {
+			add = append(add, role.RoleID)
+		} else if !reached && has[role.RoleID] {
+			remove = append(remove, role.RoleID)
+		}
+	}
+
+	return
+}
+
+// HasLeaderboardSchedule reports whether the guild's leaderboard is posted automatically
+func (g *Guild) HasLeaderboardSchedule() bool {
+	return g.LeaderboardChannelID != nil && g.LeaderboardCron != nil && g.LeaderboardDueAt != nil
 }
 
 func NewGuild(id string) *Guild {
 	return &Guild{
-		ID:       id,
-		Timezone: nil,
+		ID:        id,
+		Timezone:  nil,
+		WeekStart: time.Sunday,
 	}
 }
@@ -0,0 +1,30 @@
+package guilds_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/guilds"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilestoneRoleChanges(t *testing.T) {
+	roles := []*guilds.MilestoneRole{
+		{RoleID: "100h", Hours: 100},
+		{RoleID: "500h", Hours: 500},
+		{RoleID: "1000h", Hours: 1000},
+	}
+
+	add, remove := guilds.MilestoneRoleChanges(roles, 600*time.Hour, []string{"other"})
+	assert.Equal(t, []string{"100h", "500h"}, add)
+	assert.Empty(t, remove)
+
+	add, remove = guilds.MilestoneRoleChanges(roles, 500*time.Hour, []string{"100h", "500h"})
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+
+	// activities may be undone, or the member may make their activity private
+	add, remove = guilds.MilestoneRoleChanges(roles, 99*time.Hour, []string{"100h", "1000h", "other"})
+	assert.Empty(t, add)
+	assert.Equal(t, []string{"100h", "1000h"}, remove)
+}
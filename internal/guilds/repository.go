@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+const guildColumns = `id, timezone, week_start, leaderboard_channel_id, leaderboard_cron, leaderboard_due_at`
+
 type GuildRepository struct {
 	pool  *pgxpool.Pool
 	cache sync.Map
@@ -29,12 +32,13 @@ func (r *GuildRepository) Create(ctx context.Context, guild *Guild) error {
 
 	err = conn.QueryRow(
 		ctx,
-		`INSERT INTO guilds (id, timezone)
-			VALUES ($1, $2)
-			ON CONFLICT (id) DO UPDATE SET timezone = $2
+		`INSERT INTO guilds (id, timezone, week_start)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (id) DO UPDATE SET timezone = $2, week_start = $3
 			RETURNING id;`,
 		guild.ID,
-		guild.Timezone).
+		guild.Timezone,
+		int(guild.WeekStart)).
 		Scan(&guild.ID)
 
 	if err != nil {
@@ -60,20 +64,18 @@ func (r *GuildRepository) FindByID(ctx context.Context, id string) (*Guild, erro
 
 	defer conn.Release()
 
-	var guild Guild
-
-	err = conn.QueryRow(ctx,
-		`SELECT id, timezone
+	guild, err := scanGuild(conn.QueryRow(ctx,
+		`SELECT `+guildColumns+`
 		FROM guilds
 		WHERE id = $1;`,
-		id).Scan(&guild.ID, &guild.Timezone)
+		id))
 
 	if err != nil {
 		return nil, err
 	}
 
-	r.cache.Store(guild.ID, &guild)
-	return &guild, nil
+	r.cache.Store(guild.ID, guild)
+	return guild, nil
 }
 
 func (r *GuildRepository) FindOrCreate(ctx context.Context, id string) (*Guild, error) {
@@ -130,6 +132,201 @@ func (r *GuildRepository) SetGuildTimezone(ctx context.Context, guildID, timezon
 	return nil
 }
 
+func (r *GuildRepository) SetGuildWeekStart(ctx context.Context, guildID string, weekStart time.Weekday) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx,
+		`UPDATE guilds
+		SET week_start = $2
+		WHERE id = $1;`,
+		guildID, int(weekStart))
+
+	if err != nil {
+		return err
+	}
+
+	entry, ok := r.cache.Load(guildID)
+
+	if ok {
+		guild := entry.(*Guild)
+		guild.WeekStart = weekStart
+	}
+
+	return nil
+}
+
+// SetLeaderboardSchedule sets where and when the guild's leaderboard is posted,
+// nil values turn the posts off
+func (r *GuildRepository) SetLeaderboardSchedule(ctx context.Context, guildID string, channelID, cron *string, dueAt *time.Time) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx,
+		`UPDATE guilds
+		SET leaderboard_channel_id = $2, leaderboard_cron = $3, leaderboard_due_at = $4
+		WHERE id = $1;`,
+		guildID, channelID, cron, dueAt)
+
+	if err != nil {
+		return err
+	}
+
+	entry, ok := r.cache.Load(guildID)
+
+	if ok {
+		guild := entry.(*Guild)
+		guild.LeaderboardChannelID = channelID
+		guild.LeaderboardCron = cron
+		guild.LeaderboardDueAt = dueAt
+	}
+
+	return nil
+}
+
+// FindDueLeaderboards returns the guilds whose leaderboard should have been posted by now
+func (r *GuildRepository) FindDueLeaderboards(ctx context.Context, now time.Time) (guilds []*Guild, err error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+guildColumns+`
+		FROM guilds
+		WHERE leaderboard_channel_id IS NOT NULL
+		AND leaderboard_cron IS NOT NULL
+		AND leaderboard_due_at <= $1;`,
+		now)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var guild *Guild
+		if guild, err = scanGuild(rows); err != nil {
+			return
+		}
+
+		guilds = append(guilds, guild)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// ClaimLeaderboard moves the guild's next leaderboard post from dueAt to nextDueAt,
+// returning false if it was already moved, so that each post is only sent once
+// even when several instances are running or one restarts
+func (r *GuildRepository) ClaimLeaderboard(ctx context.Context, guildID string, dueAt, nextDueAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE guilds
+		SET leaderboard_due_at = $3
+		WHERE id = $1
+		AND leaderboard_due_at = $2;`,
+		guildID, dueAt, nextDueAt)
+
+	if err != nil {
+		return false, err
+	}
+
+	if tag.RowsAffected() != 1 {
+		return false, nil
+	}
+
+	if entry, ok := r.cache.Load(guildID); ok {
+		entry.(*Guild).LeaderboardDueAt = &nextDueAt
+	}
+
+	return true, nil
+}
+
+// FindMilestoneRoles returns the guild's milestone roles, fewest hours first
+func (r *GuildRepository) FindMilestoneRoles(ctx context.Context, guildID string) (roles []*MilestoneRole, err error) {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return
+	}
+
+	defer conn.Release()
+
+	rows, err := conn.Query(ctx,
+		`SELECT guild_id, role_id, hours
+		FROM guild_milestone_roles
+		WHERE guild_id = $1
+		ORDER BY hours, role_id;`,
+		guildID)
+
+	if err != nil {
+		return
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		role := &MilestoneRole{}
+		if err = rows.Scan(&role.GuildID, &role.RoleID, &role.Hours); err != nil {
+			return
+		}
+
+		roles = append(roles, role)
+	}
+
+	err = rows.Err()
+	return
+}
+
+// SetMilestoneRole creates the milestone role, replacing the hours of the role if it already is one
+func (r *GuildRepository) SetMilestoneRole(ctx context.Context, role *MilestoneRole) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	_, err = conn.Exec(ctx,
+		`INSERT INTO guild_milestone_roles (guild_id, role_id, hours)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (guild_id, role_id) DO UPDATE SET hours = $3;`,
+		role.GuildID, role.RoleID, role.Hours)
+
+	return err
+}
+
+// DeleteMilestoneRole stops the role from being a milestone role, returning false if it was not one
+func (r *GuildRepository) DeleteMilestoneRole(ctx context.Context, guildID, roleID string) (bool, error) {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Release()
+
+	tag, err := conn.Exec(ctx,
+		`DELETE FROM guild_milestone_roles
+		WHERE guild_id = $1
+		AND role_id = $2;`,
+		guildID, roleID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
 func (r *GuildRepository) RemoveMembers(ctx context.Context, guildID string, userID []string) error {
 	conn, err := r.pool.Acquire(ctx)
 
@@ -147,3 +344,23 @@ func (r *GuildRepository) RemoveMembers(ctx context.Context, guildID string, use
 
 	return err
 }
+
+// scanGuild scans a row selected with guildColumns
+func scanGuild(row pgx.Row) (*Guild, error) {
+	guild := &Guild{}
+
+	err := row.Scan(
+		&guild.ID,
+		&guild.Timezone,
+		&guild.WeekStart,
+		&guild.LeaderboardChannelID,
+		&guild.LeaderboardCron,
+		&guild.LeaderboardDueAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return guild, nil
+}
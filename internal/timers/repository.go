@@ -0,0 +1,151 @@
+package timers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+var ErrTimerAlreadyRunning = errors.New("user already has a running timer")
+
+const uniqueViolationCode = "23505"
+
+type TimerRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTimerRepository(pool *pgxpool.Pool) *TimerRepository {
+	return &TimerRepository{pool: pool}
+}
+
+func (r *TimerRepository) Create(ctx context.Context, t *Timer) error {
+	err := r.pool.QueryRow(
+		ctx,
+		`INSERT INTO timers (user_id, guild_id, name, primary_type, media_type, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		t.UserID,
+		t.GuildID,
+		t.Name,
+		t.PrimaryType,
+		t.MediaType,
+		t.StartedAt,
+	).Scan(&t.ID, &t.CreatedAt)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return ErrTimerAlreadyRunning
+	}
+
+	return err
+}
+
+func (r *TimerRepository) FindRunningByUserID(ctx context.Context, userID string) (*Timer, error) {
+	row := r.pool.QueryRow(ctx, `
+		SELECT id, user_id, guild_id, name, primary_type, media_type, started_at,
+			paused_at, paused_duration, stopped_at, activity_id, created_at
+		FROM timers
+		WHERE user_id = $1
+		AND stopped_at IS NULL
+	`, userID)
+
+	return scanTimer(row)
+}
+
+// FindRunningStartedBefore returns all running timers that were started before the given time
+func (r *TimerRepository) FindRunningStartedBefore(ctx context.Context, before time.Time) ([]*Timer, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, guild_id, name, primary_type, media_type, started_at,
+			paused_at, paused_duration, stopped_at, activity_id, created_at
+		FROM timers
+		WHERE started_at < $1
+		AND stopped_at IS NULL
+	`, before)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	timers := make([]*Timer, 0)
+
+	for rows.Next() {
+		t, err := scanTimer(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		timers = append(timers, t)
+	}
+
+	return timers, rows.Err()
+}
+
+func (r *TimerRepository) UpdatePause(ctx context.Context, t *Timer) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE timers
+		SET paused_at = $1, paused_duration = $2
+		WHERE id = $3
+		AND stopped_at IS NULL
+	`, t.PausedAt, t.PausedDuration, t.ID)
+
+	return err
+}
+
+// StopTx marks the timer as stopped as part of tx, returning
+// false if it had already been stopped elsewhere
+func (r *TimerRepository) StopTx(ctx context.Context, tx pgx.Tx, t *Timer) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		UPDATE timers
+		SET paused_at = $1, paused_duration = $2, stopped_at = $3
+		WHERE id = $4
+		AND stopped_at IS NULL
+	`, t.PausedAt, t.PausedDuration, t.StoppedAt, t.ID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *TimerRepository) SetActivityIDTx(ctx context.Context, tx pgx.Tx, timerID int64, activityID uint64) error {
+	_, err := tx.Exec(ctx, `
+		UPDATE timers
+		SET activity_id = $1
+		WHERE id = $2
+	`, activityID, timerID)
+
+	return err
+}
+
+func scanTimer(row pgx.Row) (*Timer, error) {
+	t := &Timer{}
+
+	err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.GuildID,
+		&t.Name,
+		&t.PrimaryType,
+		&t.MediaType,
+		&t.StartedAt,
+		&t.PausedAt,
+		&t.PausedDuration,
+		&t.StoppedAt,
+		&t.ActivityID,
+		&t.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,105 @@
+package timers
+
+import (
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+)
+
+type Timer struct {
+	ID             int64
+	UserID         string
+	GuildID        *string
+	Name           string
+	PrimaryType    string
+	MediaType      *string
+	StartedAt      time.Time
+	PausedAt       *time.Time
+	PausedDuration time.Duration
+	StoppedAt      *time.Time
+	ActivityID     *uint64
+	CreatedAt      time.Time
+}
+
+func NewTimer(userID, name, primaryType string, now time.Time) *Timer {
+	return &Timer{
+		UserID:      userID,
+		Name:        name,
+		PrimaryType: primaryType,
+		StartedAt:   now,
+	}
+}
+
+func (t *Timer) IsPaused() bool {
+	return t.PausedAt != nil
+}
+
+func (t *Timer) IsStopped() bool {
+	return t.StoppedAt != nil
+}
+
+// Elapsed returns the time the timer has been running for,
+// not counting the time spent paused
+func (t *Timer) Elapsed(now time.Time) time.Duration {
+	end := now
+
+	if t.StoppedAt != nil {
+		end = *t.StoppedAt
+	}
+
+	paused := t.PausedDuration
+
+	if t.PausedAt != nil {
+		// still paused, so the current pause has not been added yet
+		paused += end.Sub(*t.PausedAt)
+	}
+
+	return max(end.Sub(t.StartedAt)-paused, 0)
+}
+
+func (t *Timer) Pause(now time.Time) {
+	if t.IsPaused() {
+		return
+	}
+
+	t.PausedAt = &now
+}
+
+func (t *Timer) Resume(now time.Time) {
+	if !t.IsPaused() {
+		return
+	}
+
+	t.PausedDuration += now.Sub(*t.PausedAt)
+	t.PausedAt = nil
+}
+
+// Stop stops the timer, resuming it first if it was paused
+// so the final pause is accounted for in PausedDuration
+func (t *Timer) Stop(now time.Time) {
+	t.Resume(now)
+	t.StoppedAt = &now
+}
+
+// ToActivity creates the activity to be logged for the timer,
+// with the duration capped at maxDuration (if non-zero)
+func (t *Timer) ToActivity(now time.Time, maxDuration time.Duration) *activities.Activity {
+	duration := t.Elapsed(now)
+
+	if maxDuration > 0 && duration > maxDuration {
+		duration = maxDuration
+	}
+
+	a := activities.NewActivity()
+	a.UserID = t.UserID
+	a.GuildID = t.GuildID
+	a.Name = t.Name
+	a.PrimaryType = t.PrimaryType
+	a.MediaType = t.MediaType
+	a.Duration = duration
+	a.Date = now
+	a.SetMeta("timer_id", t.ID)
+	a.SetMeta("started_at", t.StartedAt)
+
+	return a
+}
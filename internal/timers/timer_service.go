@@ -0,0 +1,128 @@
+package timers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/goals"
+	"github.com/UTD-JLA/botsu/internal/users"
+)
+
+var ErrTimerAlreadyStopped = errors.New("timer has already been stopped")
+
+type TimerService struct {
+	*TimerRepository
+	ar     *activities.ActivityRepository
+	gs     *goals.GoalService
+	ggs    *goals.GuildGoalService
+	ss     *users.StreakService
+	logger *slog.Logger
+	// Timers running longer than MaxDuration are stopped by CloseExpired
+	// and are logged with a duration of at most MaxDuration
+	MaxDuration time.Duration
+}
+
+func NewTimerService(
+	repo *TimerRepository,
+	ar *activities.ActivityRepository,
+	gs *goals.GoalService,
+	ggs *goals.GuildGoalService,
+	ss *users.StreakService,
+	logger *slog.Logger,
+) *TimerService {
+	return &TimerService{TimerRepository: repo, ar: ar, gs: gs, ggs: ggs, ss: ss, logger: logger, MaxDuration: 12 * time.Hour}
+}
+
+// StopAndLog stops the timer and logs its elapsed time as an activity,
+// returning the created activity and any goals it completed
+func (s *TimerService) StopAndLog(ctx context.Context, t *Timer, now time.Time) (a *activities.Activity, completed []*goals.Goal, err error) {
+	t.Stop(now)
+
+	// the timer is only stopped if its activity is logged, so stopping can be retried
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return
+	}
+
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	stopped, err := s.StopTx(ctx, tx, t)
+	if err != nil {
+		return
+	}
+
+	if !stopped {
+		err = ErrTimerAlreadyStopped
+		return
+	}
+
+	a = t.ToActivity(now, s.MaxDuration)
+
+	if err = s.ar.CreateTx(ctx, tx, a); err != nil {
+		return
+	}
+
+	if err = s.SetActivityIDTx(ctx, tx, t.ID, a.ID); err != nil {
+		return
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return
+	}
+
+	if _, err = s.ggs.CheckCompleted(ctx, a); err != nil {
+		return
+	}
+
+	completed, err = s.gs.CheckCompleted(ctx, a)
+	return
+}
+
+// CloseExpired stops and logs all timers that have been running for longer than MaxDuration,
+// timers that fail to close are logged and left for the next call
+func (s *TimerService) CloseExpired(ctx context.Context) (closed int, err error) {
+	now := time.Now()
+
+	expired, err := s.FindRunningStartedBefore(ctx, now.Add(-s.MaxDuration))
+	if err != nil {
+		return
+	}
+
+	for _, t := range expired {
+		a, _, stopErr := s.StopAndLog(ctx, t, now)
+
+		if errors.Is(stopErr, ErrTimerAlreadyStopped) {
+			continue
+		} else if stopErr != nil {
+			s.logger.Error(
+				"Unable to close expired timer",
+				slog.Int64("timer_id", t.ID),
+				slog.String("user_id", t.UserID),
+				slog.String("err", stopErr.Error()),
+			)
+			continue
+		}
+
+		closed++
+
+		// timers stopped by the user have their streak updated by the command
+		guildID := ""
+		if a.GuildID != nil {
+			guildID = *a.GuildID
+		}
+
+		if _, streakErr := s.ss.Update(ctx, a.UserID, guildID); streakErr != nil {
+			s.logger.Error(
+				"Unable to update streak of expired timer",
+				slog.Int64("timer_id", t.ID),
+				slog.String("user_id", t.UserID),
+				slog.String("err", streakErr.Error()),
+			)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,47 @@
+package timers_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/timers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimerElapsed(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer := timers.NewTimer("1", "test", "reading", start)
+
+	assert.Equal(t, 10*time.Minute, timer.Elapsed(start.Add(10*time.Minute)))
+
+	timer.Pause(start.Add(10 * time.Minute))
+	assert.True(t, timer.IsPaused())
+	assert.Equal(t, 10*time.Minute, timer.Elapsed(start.Add(30*time.Minute)))
+
+	timer.Resume(start.Add(30 * time.Minute))
+	assert.False(t, timer.IsPaused())
+	assert.Equal(t, 20*time.Minute, timer.PausedDuration)
+	assert.Equal(t, 15*time.Minute, timer.Elapsed(start.Add(35*time.Minute)))
+
+	timer.Pause(start.Add(40 * time.Minute))
+	timer.Stop(start.Add(50 * time.Minute))
+	assert.True(t, timer.IsStopped())
+	assert.False(t, timer.IsPaused())
+	assert.Equal(t, 20*time.Minute, timer.Elapsed(start.Add(2*time.Hour)))
+}
+
+func TestTimerToActivity(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	timer := timers.NewTimer("1", "test", "listening", start)
+	end := start.Add(3 * time.Hour)
+	timer.Stop(end)
+
+	a := timer.ToActivity(end, time.Hour)
+	assert.Equal(t, time.Hour, a.Duration)
+	assert.Equal(t, "test", a.Name)
+	assert.Equal(t, "listening", a.PrimaryType)
+	assert.Equal(t, end, a.Date)
+
+	a = timer.ToActivity(end, 0)
+	assert.Equal(t, 3*time.Hour, a.Duration)
+}
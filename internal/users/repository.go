@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -36,15 +37,27 @@ func (r *UserRepository) Create(ctx context.Context, user *User) error {
 			   vn_reading_speed,
 			   book_reading_speed,
 			   manga_reading_speed,
-			   daily_goal
+			   daily_goal,
+			   goal_reminders,
+			   privacy,
+			   current_streak,
+			   best_streak,
+			   streak_freezes,
+			   streak_start
 			)
-			VALUES ($1, $2, $3, $4, $5, $6)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 			ON CONFLICT (id) DO UPDATE SET
 			    timezone = $2,
 				vn_reading_speed = $3,
 				book_reading_speed = $4,
 				manga_reading_speed = $5,
-				daily_goal = $6
+				daily_goal = $6,
+				goal_reminders = $7,
+				privacy = $8,
+				current_streak = $9,
+				best_streak = $10,
+				streak_freezes = $11,
+				streak_start = $12
 			RETURNING id;`,
 		user.ID,
 		user.Timezone,
@@ -52,6 +65,12 @@ func (r *UserRepository) Create(ctx context.Context, user *User) error {
 		user.BookReadingSpeed,
 		user.MangaReadingSpeed,
 		user.DailyGoal,
+		user.GoalReminders,
+		user.Privacy,
+		user.CurrentStreak,
+		user.BestStreak,
+		user.StreakFreezes,
+		user.StreakStart,
 	).Scan(&user.ID)
 
 	if err != nil {
@@ -84,7 +103,13 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*User, error)
        		vn_reading_speed,
        		book_reading_speed,
        		manga_reading_speed,
-       		daily_goal
+       		daily_goal,
+       		goal_reminders,
+       		privacy,
+       		current_streak,
+       		best_streak,
+       		streak_freezes,
+       		streak_start
 		FROM users
 		WHERE id = $1;`, id).Scan(
 		&user.ID,
@@ -93,6 +118,12 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*User, error)
 		&user.BookReadingSpeed,
 		&user.MangaReadingSpeed,
 		&user.DailyGoal,
+		&user.GoalReminders,
+		&user.Privacy,
+		&user.CurrentStreak,
+		&user.BestStreak,
+		&user.StreakFreezes,
+		&user.StreakStart,
 	)
 
 	if err != nil {
@@ -253,6 +284,126 @@ func (r *UserRepository) SetDailyGoal(ctx context.Context, userID string, goal i
 	return nil
 }
 
+func (r *UserRepository) SetGoalReminders(ctx context.Context, userID string, enabled bool) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	query := `
+		INSERT INTO users (id, goal_reminders)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET goal_reminders = $2;
+	`
+
+	if _, err = conn.Exec(ctx, query, userID, enabled); err != nil {
+		return err
+	}
+
+	if user := r.getCachedUser(userID); user != nil {
+		user.GoalReminders = enabled
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetPrivacy(ctx context.Context, userID string, privacy Privacy) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	query := `
+		INSERT INTO users (id, privacy)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET privacy = $2;
+	`
+
+	if _, err = conn.Exec(ctx, query, userID, privacy); err != nil {
+		return err
+	}
+
+	if user := r.getCachedUser(userID); user != nil {
+		user.Privacy = privacy
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetStreak(ctx context.Context, userID string, current, best int, start *time.Time) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	query := `
+		INSERT INTO users (id, current_streak, best_streak, streak_start)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET current_streak = $2, best_streak = $3, streak_start = $4;
+	`
+
+	if _, err = conn.Exec(ctx, query, userID, current, best, start); err != nil {
+		return err
+	}
+
+	if user := r.getCachedUser(userID); user != nil {
+		user.CurrentStreak = current
+		user.BestStreak = best
+		user.StreakStart = start
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetStreakFreezes(ctx context.Context, userID string, freezes int) error {
+	conn, err := r.pool.Acquire(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	defer conn.Release()
+
+	query := `
+		INSERT INTO users (id, streak_freezes)
+		VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET streak_freezes = $2;
+	`
+
+	if _, err = conn.Exec(ctx, query, userID, freezes); err != nil {
+		return err
+	}
+
+	if user := r.getCachedUser(userID); user != nil {
+		user.StreakFreezes = freezes
+	}
+
+	return nil
+}
+
+// GetPrivacy returns the privacy of the user, users who never
+// used the bot being public like new users
+func (r *UserRepository) GetPrivacy(ctx context.Context, userID string) (Privacy, error) {
+	user, err := r.FindByID(ctx, userID)
+
+	if errors.Is(err, pgx.ErrNoRows) {
+		return PrivacyPublic, nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return user.Privacy, nil
+}
+
 func (r *UserRepository) cacheUser(user *User) {
 	r.cache.Store(user.ID, user)
 }
@@ -0,0 +1,188 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/jackc/pgx/v5"
+)
+
+// MaxStreakFreezes is the most missed days a month users can allow to not break their streak
+const MaxStreakFreezes = 10
+
+// Streak is a user's run of days on which they met their daily goal
+type Streak struct {
+	Current int
+	Best    int
+	// Start is the first day of the current streak, the zero time if there is none
+	Start time.Time
+	// TodayMet is true if today already counts towards the streak
+	TodayMet bool
+	// FreezesLeft is the number of missed days this month that will not break the streak
+	FreezesLeft int
+}
+
+// day returns the date of t as midnight UTC, like dates read from the database
+func day(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// sameDay reports whether a and b are both nil or on the same date
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return day(*a).Equal(day(*b))
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return t.AddDate(0, 0, 1-t.Day())
+}
+
+func monthKey(t time.Time) int {
+	return t.Year()*12 + int(t.Month())
+}
+
+// CalculateStreak works out the streak of a user with the given daily totals, earliest first.
+// A day counts if its total reaches goal, or is above zero if goal is zero, and up to freezes
+// missed days each month are skipped over rather than breaking the streak. Today not counting
+// yet does not break the streak, as there is still time left to log.
+func CalculateStreak(totals []*activities.DailyTotal, goal time.Duration, freezes int, today time.Time) (s Streak) {
+	today = day(today)
+	used := make(map[int]int)
+
+	// cover reports whether every day after from and before to can be frozen,
+	// using up the freezes if so
+	cover := func(from, to time.Time) bool {
+		needed := make(map[int]int)
+
+		for d := from.AddDate(0, 0, 1); d.Before(to); d = d.AddDate(0, 0, 1) {
+			key := monthKey(d)
+			needed[key]++
+
+			if used[key]+needed[key] > freezes {
+				return false
+			}
+		}
+
+		for key, n := range needed {
+			used[key] += n
+		}
+
+		return true
+	}
+
+	var last time.Time
+
+	for _, total := range totals {
+		if total.Total < goal || total.Total <= 0 {
+			continue
+		}
+
+		d := day(total.Day)
+
+		if s.Current > 0 && cover(last, d) {
+			s.Current++
+		} else {
+			s.Current = 1
+			s.Start = d
+		}
+
+		last = d
+		s.Best = max(s.Best, s.Current)
+	}
+
+	s.TodayMet = s.Current > 0 && last.Equal(today)
+
+	if s.Current > 0 && !s.TodayMet && !cover(last, today) {
+		s.Current = 0
+	}
+
+	if s.Current == 0 {
+		s.Start = time.Time{}
+	}
+
+	s.FreezesLeft = max(freezes-used[monthKey(today)], 0)
+	return
+}
+
+type StreakService struct {
+	u  *UserRepository
+	a  *activities.ActivityRepository
+	ts *UserTimeService
+}
+
+func NewStreakService(u *UserRepository, a *activities.ActivityRepository, ts *UserTimeService) *StreakService {
+	return &StreakService{u: u, a: a, ts: ts}
+}
+
+// Update works out the user's streak from their recent activities and stores it,
+// guildID being used for the timezone of users without one. Only the days from the start
+// of the stored streak, or of last month if that is later, are read as earlier days can only
+// change through backdated or removed activities, so the best streak is never lowered
+func (s *StreakService) Update(ctx context.Context, userID, guildID string) (*Streak, error) {
+	return s.update(ctx, userID, guildID, false)
+}
+
+// Recalculate works out the user's streak from all of their activities and stores it,
+// used after activities have been imported or removed
+func (s *StreakService) Recalculate(ctx context.Context, userID, guildID string) (*Streak, error) {
+	return s.update(ctx, userID, guildID, true)
+}
+
+func (s *StreakService) update(ctx context.Context, userID, guildID string, full bool) (*Streak, error) {
+	user, err := s.u.FindByID(ctx, userID)
+
+	// users are created when they first log an activity
+	if errors.Is(err, pgx.ErrNoRows) {
+		return &Streak{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	now, err := s.ts.GetTime(ctx, userID, guildID)
+	if err != nil {
+		return nil, err
+	}
+
+	// freezes are allowed per month, so a streak may be continued over missed days
+	// as far back as the start of last month, and whole months are read so the
+	// freezes already used in them are counted
+	// streaks stored without their start day are read in full once
+	full = full || (user.CurrentStreak > 0 && user.StreakStart == nil)
+
+	var since time.Time
+	if !full {
+		since = startOfMonth(day(now)).AddDate(0, -1, 0)
+
+		if user.StreakStart != nil && user.StreakStart.Before(since) {
+			since = startOfMonth(day(*user.StreakStart))
+		}
+	}
+
+	totals, err := s.a.GetDailyTotalsByUserID(ctx, userID, guildID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	streak := CalculateStreak(totals, time.Duration(user.DailyGoal)*time.Minute, user.StreakFreezes, now)
+	if !full {
+		streak.Best = max(streak.Best, user.BestStreak)
+	}
+
+	var start *time.Time
+	if streak.Current > 0 {
+		start = &streak.Start
+	}
+
+	if streak.Current != user.CurrentStreak || streak.Best != user.BestStreak || !sameDay(start, user.StreakStart) {
+		if err = s.u.SetStreak(ctx, userID, streak.Current, streak.Best, start); err != nil {
+			return nil, err
+		}
+	}
+
+	return &streak, nil
+}
@@ -0,0 +1,68 @@
+package users_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/UTD-JLA/botsu/internal/activities"
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+// totalsOn returns daily totals of the given minutes, one day apart starting on start,
+// leaving out days of zero minutes
+func totalsOn(start time.Time, minutes ...int) []*activities.DailyTotal {
+	totals := make([]*activities.DailyTotal, 0, len(minutes))
+
+	for i, m := range minutes {
+		if m == 0 {
+			continue
+		}
+
+		totals = append(totals, &activities.DailyTotal{
+			Day:   start.AddDate(0, 0, i),
+			Total: time.Duration(m) * time.Minute,
+		})
+	}
+
+	return totals
+}
+
+func TestCalculateStreak(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	totals := totalsOn(start, 10, 60, 60, 0, 60, 60, 60, 60, 20)
+
+	// without a goal, any activity counts
+	s := users.CalculateStreak(totals, 0, 0, start.AddDate(0, 0, 8).Add(20*time.Hour))
+	assert.Equal(t, users.Streak{Current: 5, Best: 5, Start: start.AddDate(0, 0, 4), TodayMet: true}, s)
+
+	// today not being met yet does not break the streak
+	s = users.CalculateStreak(totals, 30*time.Minute, 0, start.AddDate(0, 0, 8))
+	assert.Equal(t, users.Streak{Current: 4, Best: 4, Start: start.AddDate(0, 0, 4)}, s)
+
+	// but missing yesterday does
+	s = users.CalculateStreak(totals, 30*time.Minute, 0, start.AddDate(0, 0, 9))
+	assert.Equal(t, users.Streak{Current: 0, Best: 4}, s)
+
+	// unless it is frozen
+	s = users.CalculateStreak(totals, 30*time.Minute, 2, start.AddDate(0, 0, 9))
+	assert.Equal(t, users.Streak{Current: 6, Best: 6, Start: start.AddDate(0, 0, 1), FreezesLeft: 0}, s)
+
+	s = users.CalculateStreak(totals, 30*time.Minute, 3, start.AddDate(0, 0, 8))
+	assert.Equal(t, users.Streak{Current: 6, Best: 6, Start: start.AddDate(0, 0, 1), FreezesLeft: 2}, s)
+}
+
+func TestCalculateStreakFreezesPerMonth(t *testing.T) {
+	start := time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC)
+	// a day is missed at the end of January and at the start of February
+	totals := totalsOn(start, 60, 0, 60, 0, 60)
+
+	s := users.CalculateStreak(totals, 0, 1, start.AddDate(0, 0, 4))
+	assert.Equal(t, users.Streak{Current: 3, Best: 3, Start: start, TodayMet: true, FreezesLeft: 0}, s)
+
+	// two missed days in the same month need two freezes
+	start = time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)
+	totals = totalsOn(start, 60, 0, 0, 60)
+	s = users.CalculateStreak(totals, 0, 1, start.AddDate(0, 0, 3))
+	assert.Equal(t, users.Streak{Current: 1, Best: 1, Start: start.AddDate(0, 0, 3), TodayMet: true, FreezesLeft: 1}, s)
+}
@@ -1,5 +1,37 @@
 package users
 
+import "time"
+
+// Privacy controls who besides the user can see their activity
+type Privacy string
+
+const (
+	// PrivacyPublic shows the user's activity to anyone
+	PrivacyPublic Privacy = "public"
+	// PrivacyGuild shows only the totals of the user's activity, and
+	// only to members of guilds the user is in
+	PrivacyGuild Privacy = "guild"
+	// PrivacyPrivate shows the user's activity to no one else,
+	// leaving them off of leaderboards
+	PrivacyPrivate Privacy = "private"
+)
+
+// IsValid reports whether p is one of the privacy levels
+func (p Privacy) IsValid() bool {
+	return p == PrivacyPublic || p == PrivacyGuild || p == PrivacyPrivate
+}
+
+// AllowsActivities reports whether others may see the user's individual activities and titles
+func (p Privacy) AllowsActivities() bool {
+	return p == PrivacyPublic
+}
+
+// AllowsTotals reports whether others may see the totals of the user's activity,
+// sharesGuild being whether they are looking from a guild the user is in
+func (p Privacy) AllowsTotals(sharesGuild bool) bool {
+	return p == PrivacyPublic || (p == PrivacyGuild && sharesGuild)
+}
+
 type User struct {
 	ID                      string
 	Timezone                *string
@@ -7,6 +39,16 @@ type User struct {
 	BookReadingSpeed        float32
 	MangaReadingSpeed       float32
 	DailyGoal               int
+	// GoalReminders is false if the user opted out of goal reminder DMs
+	GoalReminders bool
+	Privacy       Privacy
+	// CurrentStreak and BestStreak are kept up to date by StreakService
+	CurrentStreak int
+	BestStreak    int
+	// StreakStart is the first day of the current streak, nil if there is none
+	StreakStart *time.Time
+	// StreakFreezes is the number of missed days each month that do not break the streak
+	StreakFreezes int
 }
 
 func NewUser(id string) *User {
@@ -17,5 +59,7 @@ func NewUser(id string) *User {
 		BookReadingSpeed:        0,
 		MangaReadingSpeed:       0,
 		DailyGoal:               0,
+		GoalReminders:           true,
+		Privacy:                 PrivacyPublic,
 	}
 }
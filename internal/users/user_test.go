@@ -0,0 +1,24 @@
+package users_test
+
+import (
+	"testing"
+
+	"github.com/UTD-JLA/botsu/internal/users"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrivacy(t *testing.T) {
+	assert.True(t, users.PrivacyPublic.AllowsActivities())
+	assert.True(t, users.PrivacyPublic.AllowsTotals(false))
+
+	assert.False(t, users.PrivacyGuild.AllowsActivities())
+	assert.True(t, users.PrivacyGuild.AllowsTotals(true))
+	assert.False(t, users.PrivacyGuild.AllowsTotals(false))
+
+	assert.False(t, users.PrivacyPrivate.AllowsActivities())
+	assert.False(t, users.PrivacyPrivate.AllowsTotals(true))
+
+	assert.True(t, users.PrivacyGuild.IsValid())
+	assert.False(t, users.Privacy("friends").IsValid())
+	assert.Equal(t, users.PrivacyPublic, users.NewUser("1").Privacy)
+}
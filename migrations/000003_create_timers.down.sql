@@ -0,0 +1,2 @@
+DROP INDEX timers_running_user_id_index;
+DROP TABLE timers;
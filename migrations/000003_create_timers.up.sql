@@ -0,0 +1,17 @@
+CREATE TABLE timers (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(20) NOT NULL,
+    guild_id VARCHAR(20),
+    name TEXT NOT NULL,
+    primary_type activity_primary_type NOT NULL,
+    media_type activity_media_type,
+    started_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    paused_at TIMESTAMP WITH TIME ZONE,
+    paused_duration BIGINT NOT NULL DEFAULT 0,
+    stopped_at TIMESTAMP WITH TIME ZONE,
+    activity_id BIGINT REFERENCES activities(id),
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+
+-- a user can only have a single running timer
+CREATE UNIQUE INDEX timers_running_user_id_index ON timers (user_id) WHERE stopped_at IS NULL;
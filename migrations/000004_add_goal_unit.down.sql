@@ -0,0 +1,3 @@
+ALTER TABLE goals DROP COLUMN unit;
+
+DROP TYPE goal_unit;
@@ -0,0 +1,3 @@
+CREATE TYPE goal_unit AS ENUM('duration', 'characters', 'pages', 'episodes');
+
+ALTER TABLE goals ADD COLUMN unit goal_unit NOT NULL DEFAULT 'duration';
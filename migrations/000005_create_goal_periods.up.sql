@@ -0,0 +1,11 @@
+CREATE TABLE goal_periods (
+    id BIGSERIAL PRIMARY KEY,
+    goal_id BIGINT NOT NULL REFERENCES goals(id),
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    achieved BIGINT NOT NULL,
+    target BIGINT NOT NULL,
+    met BOOLEAN NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    UNIQUE (goal_id, period_end)
+);
@@ -0,0 +1,5 @@
+DROP INDEX goals_reminder_due_at_index;
+
+ALTER TABLE users DROP COLUMN goal_reminders;
+ALTER TABLE goals DROP COLUMN reminded_due_at;
+ALTER TABLE goals DROP COLUMN reminder;
@@ -0,0 +1,5 @@
+ALTER TABLE goals ADD COLUMN reminder BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD COLUMN reminded_due_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE users ADD COLUMN goal_reminders BOOLEAN NOT NULL DEFAULT TRUE;
+
+CREATE INDEX goals_reminder_due_at_index ON goals (due_at) WHERE reminder > 0 AND deleted_at IS NULL;
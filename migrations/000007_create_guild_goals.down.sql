@@ -0,0 +1,2 @@
+DROP TABLE guild_goal_contributions;
+DROP TABLE guild_goals;
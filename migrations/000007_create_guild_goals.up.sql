@@ -0,0 +1,28 @@
+CREATE TABLE guild_goals (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id VARCHAR(20) NOT NULL REFERENCES guilds(id),
+    channel_id VARCHAR(20) NOT NULL,
+    created_by VARCHAR(20) NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    activity_type activity_primary_type,
+    media_type activity_media_type,
+    youtube_channels TEXT[],
+    unit goal_unit NOT NULL DEFAULT 'duration',
+    cron TEXT NOT NULL,
+    target BIGINT NOT NULL,
+    current BIGINT NOT NULL DEFAULT 0,
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    announced_due_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP NOT NULL DEFAULT (NOW() AT TIME ZONE 'utc'),
+    deleted_at TIMESTAMP
+);
+
+CREATE INDEX guild_goals_guild_id_index ON guild_goals (guild_id);
+
+CREATE TABLE guild_goal_contributions (
+    PRIMARY KEY (guild_goal_id, due_at, user_id),
+    guild_goal_id BIGINT NOT NULL REFERENCES guild_goals(id),
+    due_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    user_id VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0
+);
@@ -0,0 +1 @@
+ALTER TABLE goals ADD COLUMN paused_at TIMESTAMP WITH TIME ZONE;
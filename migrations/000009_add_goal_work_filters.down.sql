@@ -0,0 +1,9 @@
+ALTER TABLE guild_goals DROP COLUMN name_pattern;
+ALTER TABLE guild_goals DROP COLUMN tags;
+ALTER TABLE guild_goals DROP COLUMN vndb_ids;
+ALTER TABLE guild_goals DROP COLUMN anidb_ids;
+
+ALTER TABLE goals DROP COLUMN name_pattern;
+ALTER TABLE goals DROP COLUMN tags;
+ALTER TABLE goals DROP COLUMN vndb_ids;
+ALTER TABLE goals DROP COLUMN anidb_ids;
@@ -0,0 +1,9 @@
+ALTER TABLE goals ADD COLUMN anidb_ids TEXT[];
+ALTER TABLE goals ADD COLUMN vndb_ids TEXT[];
+ALTER TABLE goals ADD COLUMN tags TEXT[];
+ALTER TABLE goals ADD COLUMN name_pattern TEXT;
+
+ALTER TABLE guild_goals ADD COLUMN anidb_ids TEXT[];
+ALTER TABLE guild_goals ADD COLUMN vndb_ids TEXT[];
+ALTER TABLE guild_goals ADD COLUMN tags TEXT[];
+ALTER TABLE guild_goals ADD COLUMN name_pattern TEXT;
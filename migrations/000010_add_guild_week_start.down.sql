@@ -0,0 +1 @@
+ALTER TABLE guilds DROP COLUMN week_start;
@@ -0,0 +1 @@
+ALTER TABLE guilds ADD COLUMN week_start SMALLINT NOT NULL DEFAULT 0 CHECK (week_start BETWEEN 0 AND 6);
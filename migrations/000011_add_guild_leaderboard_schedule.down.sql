@@ -0,0 +1,5 @@
+DROP INDEX guilds_leaderboard_due_at_index;
+
+ALTER TABLE guilds DROP COLUMN leaderboard_due_at;
+ALTER TABLE guilds DROP COLUMN leaderboard_cron;
+ALTER TABLE guilds DROP COLUMN leaderboard_channel_id;
@@ -0,0 +1,5 @@
+ALTER TABLE guilds ADD COLUMN leaderboard_channel_id VARCHAR(20);
+ALTER TABLE guilds ADD COLUMN leaderboard_cron TEXT;
+ALTER TABLE guilds ADD COLUMN leaderboard_due_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX guilds_leaderboard_due_at_index ON guilds (leaderboard_due_at) WHERE leaderboard_cron IS NOT NULL;
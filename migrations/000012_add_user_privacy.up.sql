@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN privacy TEXT NOT NULL DEFAULT 'public' CHECK (privacy IN ('public', 'guild', 'private'));
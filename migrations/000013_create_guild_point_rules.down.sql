@@ -0,0 +1 @@
+DROP TABLE guild_point_rules;
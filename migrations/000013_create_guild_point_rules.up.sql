@@ -0,0 +1,9 @@
+CREATE TABLE guild_point_rules (
+    guild_id VARCHAR(20) NOT NULL REFERENCES guilds(id),
+    -- empty for the rules of media types without rules of their own
+    media_type TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL CHECK (unit IN ('minutes', 'characters', 'pages', 'episodes')),
+    amount DOUBLE PRECISION NOT NULL CHECK (amount > 0),
+    points DOUBLE PRECISION NOT NULL CHECK (points >= 0),
+    PRIMARY KEY (guild_id, media_type, unit)
+);
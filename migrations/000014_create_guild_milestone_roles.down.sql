@@ -0,0 +1 @@
+DROP TABLE guild_milestone_roles;
@@ -0,0 +1,6 @@
+CREATE TABLE guild_milestone_roles (
+    guild_id VARCHAR(20) NOT NULL REFERENCES guilds(id),
+    role_id VARCHAR(20) NOT NULL,
+    hours INTEGER NOT NULL CHECK (hours > 0),
+    PRIMARY KEY (guild_id, role_id)
+);
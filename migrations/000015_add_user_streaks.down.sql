@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN streak_freezes;
+ALTER TABLE users DROP COLUMN best_streak;
+ALTER TABLE users DROP COLUMN current_streak;
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN current_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN best_streak INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN streak_freezes INTEGER NOT NULL DEFAULT 0 CHECK (streak_freezes >= 0);
@@ -0,0 +1,6 @@
+CREATE TABLE achievements (
+    user_id VARCHAR(20) NOT NULL REFERENCES users(id),
+    achievement_id TEXT NOT NULL,
+    unlocked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, achievement_id)
+);
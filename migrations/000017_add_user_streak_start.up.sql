@@ -0,0 +1 @@
+ALTER TABLE users ADD COLUMN streak_start DATE;
@@ -0,0 +1,209 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+
+	"golang.org/x/image/font"
+)
+
+// Series is a named set of values, one for each label of a chart
+type Series struct {
+	Name   string
+	Values []float64
+	Color  color.Color
+}
+
+const (
+	paddingX     = 10
+	paddingY     = 30
+	tickCount    = 5
+	tickLabelGap = 6
+	legendBox    = 12
+	legendGap    = 10
+)
+
+// axes is the plot area of a bar or line chart with its scale
+type axes struct {
+	face   font.Face
+	theme  Theme
+	labels []string
+	plot   image.Rectangle
+	max    float64
+	step   float64
+}
+
+// newAxes lays out a chart of the given size, leaving room for the
+// legend, tick labels and category labels around the plot area
+func newAxes(width, height int, theme Theme, labels []string, series []Series, maxValue float64) *axes {
+	a := &axes{
+		face:   newFace(12),
+		theme:  theme,
+		labels: labels,
+	}
+
+	a.max, a.step = niceScale(maxValue, tickCount)
+
+	yLabelWidth := 0
+	for i := 0; i <= int(math.Round(a.max/a.step)); i++ {
+		yLabelWidth = max(yLabelWidth, textWidth(a.face, formatTick(float64(i)*a.step, a.step)))
+	}
+
+	lineHeight := a.face.Metrics().Height.Ceil()
+	top := paddingY
+
+	if hasLegend(series) {
+		top += legendHeight(a.face, series, width) + legendGap
+	}
+
+	a.plot = image.Rect(
+		paddingX+yLabelWidth+tickLabelGap,
+		top,
+		width-paddingX,
+		height-paddingY-lineHeight-tickLabelGap,
+	)
+
+	return a
+}
+
+func hasLegend(series []Series) bool {
+	return len(series) > 1
+}
+
+// y returns the vertical position of value v
+func (a *axes) y(v float64) float64 {
+	return float64(a.plot.Max.Y) - v/a.max*float64(a.plot.Dy())
+}
+
+// categoryWidth is the horizontal space available to each label
+func (a *axes) categoryWidth() float64 {
+	if len(a.labels) == 0 {
+		return float64(a.plot.Dx())
+	}
+
+	return float64(a.plot.Dx()) / float64(len(a.labels))
+}
+
+// x returns the horizontal center of the i-th category
+func (a *axes) x(i int) float64 {
+	return float64(a.plot.Min.X) + (float64(i)+0.5)*a.categoryWidth()
+}
+
+// drawGrid draws the grid lines and tick labels
+func (a *axes) drawGrid(img draw.Image) {
+	lineHeight := a.face.Metrics().Height.Ceil()
+
+	for i := 0; i <= int(math.Round(a.max/a.step)); i++ {
+		value := float64(i) * a.step
+		y := int(math.Round(a.y(value)))
+
+		lineColor := a.theme.Grid
+		if i == 0 {
+			lineColor = a.theme.Axis
+		}
+
+		fillRect(img, image.Rect(a.plot.Min.X, y, a.plot.Max.X, y+1), lineColor)
+
+		label := formatTick(value, a.step)
+		drawText(img, a.face, a.theme.Text, a.plot.Min.X-tickLabelGap-textWidth(a.face, label), y-lineHeight/2, label)
+	}
+}
+
+// drawLabels draws the category labels, skipping some when they would overlap
+func (a *axes) drawLabels(img draw.Image) {
+	if len(a.labels) == 0 {
+		return
+	}
+
+	widest := 0
+	for _, label := range a.labels {
+		widest = max(widest, textWidth(a.face, label))
+	}
+
+	every := max(1, int(math.Ceil(float64(widest+tickLabelGap)/a.categoryWidth())))
+
+	for i, label := range a.labels {
+		if i%every != 0 {
+			continue
+		}
+
+		x := int(math.Round(a.x(i))) - textWidth(a.face, label)/2
+		drawText(img, a.face, a.theme.Text, x, a.plot.Max.Y+tickLabelGap, label)
+	}
+}
+
+// drawThreshold draws a dashed horizontal line at value, in the axis color if c is nil
+func (a *axes) drawThreshold(img draw.Image, value float64, c color.Color) {
+	if value == 0 || value > a.max {
+		return
+	}
+
+	if c == nil {
+		c = a.theme.Axis
+	}
+
+	y := a.y(value)
+	drawLine(img, float64(a.plot.Min.X), y, float64(a.plot.Max.X), y, 1, 5, c)
+}
+
+// drawLegend draws the name of each series centered above the plot area
+func (a *axes) drawLegend(img draw.Image, series []Series) {
+	if !hasLegend(series) {
+		return
+	}
+
+	drawLegend(img, a.face, a.theme.Text, paddingY, series)
+}
+
+// legendRows splits the series into the rows of a legend no wider than width
+func legendRows(face font.Face, series []Series, width int) (rows [][]Series) {
+	rowWidth := 0
+
+	for i, s := range series {
+		itemWidth := legendBox + legendGap/2 + textWidth(face, s.Name)
+
+		if len(rows) == 0 || rowWidth+legendGap+itemWidth > width {
+			rows = append(rows, nil)
+			rowWidth = itemWidth
+		} else {
+			rowWidth += legendGap + itemWidth
+		}
+
+		rows[len(rows)-1] = append(rows[len(rows)-1], series[i])
+	}
+
+	return
+}
+
+// legendHeight returns the height of the legend drawn by drawLegend
+func legendHeight(face font.Face, series []Series, width int) int {
+	rows := len(legendRows(face, series, width-2*paddingX))
+	return rows*face.Metrics().Height.Ceil() + (rows-1)*legendGap/2
+}
+
+// drawLegend draws colored boxes with the name of each series in centered rows starting at y
+func drawLegend(img draw.Image, face font.Face, textColor color.Color, y int, series []Series) {
+	lineHeight := face.Metrics().Height.Ceil()
+
+	for _, row := range legendRows(face, series, img.Bounds().Dx()-2*paddingX) {
+		width := -legendGap
+		for _, s := range row {
+			width += legendGap + legendBox + legendGap/2 + textWidth(face, s.Name)
+		}
+
+		x := (img.Bounds().Dx() - width) / 2
+		boxY := y + (lineHeight-legendBox)/2
+
+		for _, s := range row {
+			fillRect(img, image.Rect(x, boxY, x+legendBox, boxY+legendBox), s.Color)
+			x += legendBox + legendGap/2
+
+			drawText(img, face, textColor, x, y, s.Name)
+			x += textWidth(face, s.Name) + legendGap
+		}
+
+		y += lineHeight + legendGap/2
+	}
+}
@@ -0,0 +1,107 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+)
+
+// fraction of each label's space filled by its bars
+const barGroupWidth = 0.72
+
+// BarChart draws a bar for each label of every series,
+// side by side or stacked on top of each other
+type BarChart struct {
+	Width   int
+	Height  int
+	Theme   Theme
+	Labels  []string
+	Series  []Series
+	Stacked bool
+	// Threshold draws a dashed horizontal line at its value when non-zero
+	Threshold      float64
+	ThresholdColor color.Color
+}
+
+func (c *BarChart) maxValue() (m float64) {
+	for i := range c.Labels {
+		total := 0.0
+
+		for _, s := range c.Series {
+			v := seriesValue(s, i)
+
+			if c.Stacked {
+				total += v
+			} else {
+				total = max(total, v)
+			}
+		}
+
+		m = max(m, total)
+	}
+
+	return max(m, c.Threshold)
+}
+
+func seriesValue(s Series, i int) float64 {
+	if i >= len(s.Values) || s.Values[i] < 0 {
+		return 0
+	}
+
+	return s.Values[i]
+}
+
+// Image draws the chart
+func (c *BarChart) Image() image.Image {
+	img := newCanvas(c.Width, c.Height, c.Theme.Background)
+	a := newAxes(c.Width, c.Height, c.Theme, c.Labels, c.Series, c.maxValue())
+
+	a.drawGrid(img)
+	a.drawLegend(img, c.Series)
+
+	groupWidth := a.categoryWidth() * barGroupWidth
+	barWidth := groupWidth
+
+	if !c.Stacked && len(c.Series) > 0 {
+		barWidth /= float64(len(c.Series))
+	}
+
+	for i := range c.Labels {
+		left := a.x(i) - groupWidth/2
+		base := 0.0
+
+		for j, s := range c.Series {
+			v := seriesValue(s, i)
+			x := left
+
+			if !c.Stacked {
+				x += float64(j) * barWidth
+			}
+
+			r := image.Rect(
+				int(math.Round(x)),
+				int(math.Round(a.y(base+v))),
+				int(math.Round(x+barWidth)),
+				int(math.Round(a.y(base))),
+			)
+
+			fillRect(img, r, s.Color)
+
+			if c.Stacked {
+				base += v
+			}
+		}
+	}
+
+	a.drawLabels(img)
+	a.drawThreshold(img, c.Threshold, c.ThresholdColor)
+
+	return img
+}
+
+// Render writes the chart as a PNG image
+func (c *BarChart) Render(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
@@ -0,0 +1,101 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/font"
+)
+
+const (
+	cardPadding = 30
+	cardGap     = 20
+	cardColumns = 2
+	cardAccent  = 6
+)
+
+// CardStat is one labelled value on a card
+type CardStat struct {
+	Label string
+	Value string
+}
+
+// Card draws a title over a grid of stats, two to a row
+type Card struct {
+	Width    int
+	Theme    Theme
+	Accent   color.Color
+	Title    string
+	Subtitle string
+	Stats    []CardStat
+}
+
+// fitText shortens s with an ellipsis until it is at most width wide
+func fitText(face font.Face, s string, width int) string {
+	if textWidth(face, s) <= width {
+		return s
+	}
+
+	runes := []rune(s)
+	for len(runes) > 0 {
+		runes = runes[:len(runes)-1]
+		if shortened := string(runes) + "…"; textWidth(face, shortened) <= width {
+			return shortened
+		}
+	}
+
+	return ""
+}
+
+// Image draws the card
+func (c *Card) Image() image.Image {
+	titleFace := newFace(32)
+	subtitleFace := newFace(18)
+	labelFace := newFace(14)
+	valueFace := newFace(22)
+
+	titleHeight := titleFace.Metrics().Height.Ceil()
+	subtitleHeight := subtitleFace.Metrics().Height.Ceil()
+	labelHeight := labelFace.Metrics().Height.Ceil()
+	valueHeight := valueFace.Metrics().Height.Ceil()
+
+	rows := (len(c.Stats) + cardColumns - 1) / cardColumns
+	statHeight := labelHeight + valueHeight
+	columnWidth := (c.Width - 2*cardPadding - (cardColumns-1)*cardGap) / cardColumns
+
+	top := cardAccent + cardPadding + titleHeight
+	if c.Subtitle != "" {
+		top += subtitleHeight
+	}
+
+	top += cardGap
+	height := top + rows*statHeight + max(rows-1, 0)*cardGap + cardPadding
+
+	img := newCanvas(c.Width, height, c.Theme.Background)
+	fillRect(img, image.Rect(0, 0, c.Width, cardAccent), c.Accent)
+
+	y := cardAccent + cardPadding
+	drawText(img, titleFace, c.Accent, cardPadding, y, fitText(titleFace, c.Title, c.Width-2*cardPadding))
+	y += titleHeight
+
+	if c.Subtitle != "" {
+		drawText(img, subtitleFace, c.Theme.Text, cardPadding, y, fitText(subtitleFace, c.Subtitle, c.Width-2*cardPadding))
+	}
+
+	for i, stat := range c.Stats {
+		x := cardPadding + (i%cardColumns)*(columnWidth+cardGap)
+		y := top + (i/cardColumns)*(statHeight+cardGap)
+
+		drawText(img, labelFace, c.Theme.Text, x, y, fitText(labelFace, stat.Label, columnWidth))
+		drawText(img, valueFace, color.White, x, y+labelHeight, fitText(valueFace, stat.Value, columnWidth))
+	}
+
+	return img
+}
+
+// Render writes the card as a PNG image
+func (c *Card) Render(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
@@ -0,0 +1,197 @@
+// Package chart renders simple bar, line and pie charts, calendar heatmaps and stat cards as PNG images
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+	"strconv"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Theme sets the colors used for everything but the data
+type Theme struct {
+	Background color.Color
+	Text       color.Color
+	Grid       color.Color
+	Axis       color.Color
+}
+
+// DarkTheme matches the background of Discord's dark mode
+var DarkTheme = Theme{
+	Background: color.RGBA{0x23, 0x24, 0x28, 0xff},
+	Text:       color.RGBA{0x9e, 0x9e, 0x9e, 0xff},
+	Grid:       color.RGBA{0x2b, 0x2d, 0x31, 0xff},
+	Axis:       color.RGBA{0x9e, 0x9e, 0x9e, 0xff},
+}
+
+// DefaultPalette is used for pie slices when no colors are given
+var DefaultPalette = []color.Color{
+	color.RGBA{0x36, 0xa2, 0xeb, 0xff},
+	color.RGBA{0xff, 0x63, 0x84, 0xff},
+	color.RGBA{0xff, 0x9f, 0x40, 0xff},
+	color.RGBA{0xff, 0xcd, 0x56, 0xff},
+	color.RGBA{0x4b, 0xc0, 0xc0, 0xff},
+	color.RGBA{0x99, 0x66, 0xff, 0xff},
+	color.RGBA{0xc9, 0xcb, 0xcf, 0xff},
+	color.RGBA{0x5c, 0x6b, 0xc0, 0xff},
+	color.RGBA{0x9c, 0xcc, 0x65, 0xff},
+	color.RGBA{0xf0, 0x62, 0x92, 0xff},
+}
+
+var regularFont *opentype.Font
+
+func init() {
+	var err error
+	if regularFont, err = opentype.Parse(goregular.TTF); err != nil {
+		panic(err)
+	}
+}
+
+// newFace returns a face of the default font, falling back to
+// the fonts added with AddFallbackFont for missing glyphs
+func newFace(size float64) font.Face {
+	face := newFontFace(regularFont, size)
+	if len(fallbackFonts) == 0 {
+		return face
+	}
+
+	faces := fallbackFace{face}
+	for _, f := range fallbackFonts {
+		faces = append(faces, newFontFace(f, size))
+	}
+
+	return faces
+}
+
+func newFontFace(f *opentype.Font, size float64) font.Face {
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{
+		Size:    size,
+		DPI:     72,
+		Hinting: font.HintingFull,
+	})
+
+	// only fails for invalid options
+	if err != nil {
+		panic(err)
+	}
+
+	return face
+}
+
+func newCanvas(width, height int, background color.Color) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	if background != nil {
+		draw.Draw(img, img.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	}
+
+	return img
+}
+
+func fillRect(img draw.Image, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, image.NewUniform(c), image.Point{}, draw.Over)
+}
+
+func textWidth(face font.Face, s string) int {
+	return font.MeasureString(face, s).Ceil()
+}
+
+// drawText draws s with its top left corner at (x, y)
+func drawText(img draw.Image, face font.Face, c color.Color, x, y int, s string) {
+	d := &font.Drawer{
+		Dst:  img,
+		Src:  image.NewUniform(c),
+		Face: face,
+		Dot:  fixed.P(x, y+face.Metrics().Ascent.Ceil()),
+	}
+
+	d.DrawString(s)
+}
+
+// drawLine draws a line of the given width, leaving gaps of dash
+// pixels every dash pixels if dash is non-zero
+func drawLine(img draw.Image, x0, y0, x1, y1 float64, width float64, dash int, c color.Color) {
+	length := math.Hypot(x1-x0, y1-y0)
+	steps := int(math.Ceil(length))
+	half := width / 2
+	src := image.NewUniform(c)
+
+	for i := 0; i <= steps; i++ {
+		if dash > 0 && (i/dash)%2 == 1 {
+			continue
+		}
+
+		t := 0.0
+		if steps > 0 {
+			t = float64(i) / float64(steps)
+		}
+
+		x := x0 + (x1-x0)*t
+		y := y0 + (y1-y0)*t
+
+		r := image.Rect(
+			int(math.Round(x-half)),
+			int(math.Round(y-half)),
+			int(math.Round(x+half)),
+			int(math.Round(y+half)),
+		)
+
+		if r.Empty() {
+			r.Max = r.Min.Add(image.Pt(1, 1))
+		}
+
+		draw.Draw(img, r, src, image.Point{}, draw.Over)
+	}
+}
+
+// niceScale returns an axis maximum and tick step covering maxValue with about ticks ticks
+func niceScale(maxValue float64, ticks int) (niceMax, step float64) {
+	if maxValue <= 0 || math.IsNaN(maxValue) || math.IsInf(maxValue, 0) {
+		return 1, 1
+	}
+
+	rough := maxValue / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(rough)))
+
+	switch fraction := rough / magnitude; {
+	case fraction <= 1:
+		step = magnitude
+	case fraction <= 2:
+		step = 2 * magnitude
+	case fraction <= 5:
+		step = 5 * magnitude
+	default:
+		step = 10 * magnitude
+	}
+
+	niceMax = math.Ceil(maxValue/step) * step
+	return
+}
+
+// formatTick formats a tick value with as many decimals as the step needs
+func formatTick(v, step float64) string {
+	decimals := 0
+	if step < 1 {
+		decimals = int(math.Ceil(-math.Log10(step)))
+	}
+
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+func fillCircle(img draw.Image, cx, cy, radius float64, c color.Color) {
+	src := image.NewUniform(c)
+
+	for y := int(cy - radius); y <= int(cy+radius); y++ {
+		for x := int(cx - radius); x <= int(cx+radius); x++ {
+			if math.Hypot(float64(x)+0.5-cx, float64(y)+0.5-cy) <= radius {
+				draw.Draw(img, image.Rect(x, y, x+1, y+1), src, image.Point{}, draw.Over)
+			}
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package chart
+
+import (
+	"bytes"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+func TestNiceScale(t *testing.T) {
+	max, step := niceScale(87, 5)
+	assert.Equal(t, 100.0, max)
+	assert.Equal(t, 20.0, step)
+
+	max, step = niceScale(0.9, 5)
+	assert.InDelta(t, 1.0, max, 1e-9)
+	assert.InDelta(t, 0.2, step, 1e-9)
+
+	max, step = niceScale(0, 5)
+	assert.Equal(t, 1.0, max)
+	assert.Equal(t, 1.0, step)
+}
+
+func TestFormatTick(t *testing.T) {
+	assert.Equal(t, "60", formatTick(60, 20))
+	assert.Equal(t, "0.6", formatTick(3*0.2, 0.2))
+	assert.Equal(t, "0.05", formatTick(0.05, 0.05))
+}
+
+func TestLegendRows(t *testing.T) {
+	face := newFace(12)
+	series := []Series{{Name: "Listening"}, {Name: "Reading"}, {Name: "Watching"}}
+
+	assert.Len(t, legendRows(face, series, 1000), 1)
+	assert.Len(t, legendRows(face, series, 1), 3)
+}
+
+func TestHeatmapLevel(t *testing.T) {
+	assert.Equal(t, 0, heatmapLevel(0, 0))
+	assert.Equal(t, 0, heatmapLevel(0, 60))
+	assert.Equal(t, 1, heatmapLevel(1, 60))
+	assert.Equal(t, 2, heatmapLevel(30, 60))
+	assert.Equal(t, 4, heatmapLevel(60, 60))
+}
+
+func TestFitText(t *testing.T) {
+	face := newFace(12)
+
+	assert.Equal(t, "short", fitText(face, "short", 1000))
+
+	fitted := fitText(face, "a much longer title than fits", 60)
+	assert.LessOrEqual(t, textWidth(face, fitted), 60)
+	assert.True(t, strings.HasSuffix(fitted, "…"))
+}
+
+func TestRender(t *testing.T) {
+	series := []Series{
+		{Name: "Anime", Values: []float64{30, 0, 45}, Color: color.RGBA{0xff, 0, 0, 0xff}},
+		{Name: "Book", Values: []float64{10, 20, 5}, Color: color.RGBA{0, 0, 0xff, 0xff}},
+	}
+
+	renderers := map[string]func(*bytes.Buffer) error{
+		"bar": func(b *bytes.Buffer) error {
+			c := &BarChart{Width: 500, Height: 300, Theme: DarkTheme, Labels: []string{"a", "b", "c"}, Series: series, Threshold: 60}
+			return c.Render(b)
+		},
+		"stacked bar": func(b *bytes.Buffer) error {
+			c := &BarChart{Width: 500, Height: 300, Theme: DarkTheme, Labels: []string{"a", "b", "c"}, Series: series, Stacked: true}
+			return c.Render(b)
+		},
+		"line": func(b *bytes.Buffer) error {
+			c := &LineChart{Width: 500, Height: 300, Theme: DarkTheme, Labels: []string{"a", "b", "c"}, Series: series}
+			return c.Render(b)
+		},
+		"pie": func(b *bytes.Buffer) error {
+			c := &PieChart{Width: 600, Height: 500, Labels: []string{"@a", "@b"}, Values: []float64{3, 1}}
+			return c.Render(b)
+		},
+		"heatmap": func(b *bytes.Buffer) error {
+			c := &CalendarHeatmap{Theme: DarkTheme, Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Values: make([]float64, 366), Color: color.White}
+			return c.Render(b)
+		},
+		"card": func(b *bytes.Buffer) error {
+			c := &Card{Width: 600, Theme: DarkTheme, Accent: color.White, Title: "2024", Stats: []CardStat{{"Total", "12h"}, {"Top", "a"}, {"Days", "3"}}}
+			return c.Render(b)
+		},
+		"empty": func(b *bytes.Buffer) error {
+			c := &BarChart{Width: 500, Height: 300, Theme: DarkTheme}
+			return c.Render(b)
+		},
+	}
+
+	for name, render := range renderers {
+		t.Run(name, func(t *testing.T) {
+			b := &bytes.Buffer{}
+			require.NoError(t, render(b))
+
+			img, err := png.Decode(b)
+			require.NoError(t, err)
+			assert.Positive(t, img.Bounds().Dx())
+		})
+	}
+}
+
+// runeFace only has glyphs for the given runes
+type runeFace struct {
+	font.Face
+	runes string
+}
+
+func (f runeFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	if !strings.ContainsRune(f.runes, r) {
+		return 0, false
+	}
+
+	return fixed.I(12), true
+}
+
+func TestFallbackFace(t *testing.T) {
+	regular := newFontFace(regularFont, 12)
+	japanese := runeFace{Face: regular, runes: "日本語"}
+	face := fallbackFace{regular, japanese}
+
+	assert.Equal(t, regular, face.faceFor('a'))
+	assert.Equal(t, japanese, face.faceFor('語'))
+	// neither has a glyph, so the default font draws its missing glyph box
+	assert.Equal(t, regular, face.faceFor('あ'))
+
+	assert.Equal(t, 36, textWidth(face, "日本語"))
+	assert.Equal(t, fixed.Int26_6(0), face.Kern('a', '日'))
+}
+
+func TestRenderJapanese(t *testing.T) {
+	path := os.Getenv("BOTSU_CHART_FONT")
+	if path == "" {
+		path = "/usr/share/fonts/opentype/noto/NotoSansCJK-Regular.ttc"
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Skipf("no Japanese font to fall back to: %v", err)
+	}
+
+	require.NoError(t, AddFallbackFont(data))
+	t.Cleanup(func() { fallbackFonts = nil })
+
+	face := newFace(12)
+	for _, r := range "日本語のひらがなとカタカナ" {
+		_, ok := face.GlyphAdvance(r)
+		assert.True(t, ok, "missing glyph for %q", r)
+	}
+
+	c := &PieChart{Width: 600, Height: 500, Labels: []string{"ゆるキャン△", "葬送のフリーレン"}, Values: []float64{3, 1}}
+
+	b := &bytes.Buffer{}
+	require.NoError(t, c.Render(b))
+
+	_, err = png.Decode(b)
+	require.NoError(t, err)
+}
@@ -0,0 +1,79 @@
+package chart
+
+import (
+	"errors"
+	"image"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// fallbackFonts are used for the characters Go Regular has no glyphs for
+var fallbackFonts []*opentype.Font
+
+// AddFallbackFont parses a TrueType or OpenType font, or the first font of a collection,
+// to draw the characters that the default font has no glyphs for, such as Japanese.
+// It must be called before any chart is rendered
+func AddFallbackFont(data []byte) error {
+	collection, err := opentype.ParseCollection(data)
+	if err != nil {
+		return err
+	}
+
+	f, err := collection.Font(0)
+	if err != nil {
+		return err
+	}
+
+	fallbackFonts = append(fallbackFonts, f)
+	return nil
+}
+
+// fallbackFace draws each character with the first of its faces that has
+// a glyph for it, the first face being used for the metrics
+type fallbackFace []font.Face
+
+func (f fallbackFace) faceFor(r rune) font.Face {
+	for _, face := range f {
+		if _, ok := face.GlyphAdvance(r); ok {
+			return face
+		}
+	}
+
+	return f[0]
+}
+
+func (f fallbackFace) Close() error {
+	var errs []error
+	for _, face := range f {
+		errs = append(errs, face.Close())
+	}
+
+	return errors.Join(errs...)
+}
+
+func (f fallbackFace) Glyph(dot fixed.Point26_6, r rune) (image.Rectangle, image.Image, image.Point, fixed.Int26_6, bool) {
+	return f.faceFor(r).Glyph(dot, r)
+}
+
+func (f fallbackFace) GlyphBounds(r rune) (fixed.Rectangle26_6, fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphBounds(r)
+}
+
+func (f fallbackFace) GlyphAdvance(r rune) (fixed.Int26_6, bool) {
+	return f.faceFor(r).GlyphAdvance(r)
+}
+
+func (f fallbackFace) Kern(r0, r1 rune) fixed.Int26_6 {
+	face := f.faceFor(r0)
+	if face != f.faceFor(r1) {
+		return 0
+	}
+
+	return face.Kern(r0, r1)
+}
+
+func (f fallbackFace) Metrics() font.Metrics {
+	return f[0].Metrics()
+}
@@ -0,0 +1,133 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"time"
+)
+
+const (
+	heatmapCell    = 12
+	heatmapGap     = 3
+	heatmapPadding = 20
+	heatmapLevels  = 4
+)
+
+// CalendarHeatmap draws a grid with a column for each week and a
+// cell for each day, shaded by the day's value relative to the highest
+type CalendarHeatmap struct {
+	Theme Theme
+	// Start is the day of the first value
+	Start time.Time
+	// Values has one value for each day starting from Start
+	Values    []float64
+	Color     color.Color
+	WeekStart time.Weekday
+}
+
+// row returns the row of the day, counting from WeekStart
+func (c *CalendarHeatmap) row(day time.Time) int {
+	return (int(day.Weekday()) - int(c.WeekStart) + 7) % 7
+}
+
+// heatmapLevel returns the shade of value, from zero for no activity up to heatmapLevels
+func heatmapLevel(value, highest float64) int {
+	if value <= 0 || highest <= 0 {
+		return 0
+	}
+
+	return min(heatmapLevels, max(1, int(math.Ceil(value/highest*heatmapLevels))))
+}
+
+func (c *CalendarHeatmap) levelColor(level int) color.Color {
+	empty := blend(c.Theme.Background, c.Theme.Text, 0.12)
+
+	if level == 0 {
+		return empty
+	}
+
+	return blend(empty, c.Color, float64(level)/heatmapLevels)
+}
+
+// blend mixes a and b, returning a when t is 0 and b when t is 1
+func blend(a, b color.Color, t float64) color.Color {
+	ar, ag, ab, aa := a.RGBA()
+	br, bg, bb, ba := b.RGBA()
+
+	mix := func(x, y uint32) uint8 {
+		return uint8((float64(x)*(1-t) + float64(y)*t) / 0x101)
+	}
+
+	return color.RGBA{mix(ar, br), mix(ag, bg), mix(ab, bb), mix(aa, ba)}
+}
+
+// Image draws the chart
+func (c *CalendarHeatmap) Image() image.Image {
+	face := newFace(12)
+	lineHeight := face.Metrics().Height.Ceil()
+	pitch := heatmapCell + heatmapGap
+
+	start := time.Date(c.Start.Year(), c.Start.Month(), c.Start.Day(), 0, 0, 0, 0, time.UTC)
+	offset := c.row(start)
+	weeks := (offset + len(c.Values) + 6) / 7
+
+	dayLabelWidth := textWidth(face, "Wed") + heatmapGap*2
+	left := heatmapPadding + dayLabelWidth
+	top := heatmapPadding + lineHeight + heatmapGap
+
+	width := max(left+weeks*pitch+heatmapPadding, 2*heatmapPadding+textWidth(face, "Less More")+(heatmapLevels+1)*pitch+2*heatmapGap)
+	height := top + 7*pitch + heatmapGap + lineHeight + heatmapPadding
+
+	img := newCanvas(width, height, c.Theme.Background)
+
+	// label every other day, starting from the second row
+	for row := 1; row < 7; row += 2 {
+		label := time.Weekday((int(c.WeekStart) + row) % 7).String()[:3]
+		drawText(img, face, c.Theme.Text, heatmapPadding, top+row*pitch+(heatmapCell-lineHeight)/2, label)
+	}
+
+	highest := 0.0
+	for _, v := range c.Values {
+		highest = max(highest, v)
+	}
+
+	lastMonthLabel := -1
+
+	for i, v := range c.Values {
+		day := start.AddDate(0, 0, i)
+		column := (offset + i) / 7
+		x := left + column*pitch
+		y := top + c.row(day)*pitch
+
+		fillRect(img, image.Rect(x, y, x+heatmapCell, y+heatmapCell), c.levelColor(heatmapLevel(v, highest)))
+
+		// label the column of the first day of each month, when there is space
+		if (i == 0 || day.Day() == 1) && (lastMonthLabel < 0 || column-lastMonthLabel >= 3) {
+			drawText(img, face, c.Theme.Text, x, heatmapPadding, day.Month().String()[:3])
+			lastMonthLabel = column
+		}
+	}
+
+	// legend in the bottom right corner
+	legendTop := top + 7*pitch + heatmapGap
+	x := width - heatmapPadding - textWidth(face, "More")
+	drawText(img, face, c.Theme.Text, x, legendTop, "More")
+	x -= heatmapGap + (heatmapLevels+1)*pitch
+
+	for level := 0; level <= heatmapLevels; level++ {
+		cellY := legendTop + (lineHeight-heatmapCell)/2
+		fillRect(img, image.Rect(x+level*pitch, cellY, x+level*pitch+heatmapCell, cellY+heatmapCell), c.levelColor(level))
+	}
+
+	drawText(img, face, c.Theme.Text, x-heatmapGap-textWidth(face, "Less"), legendTop, "Less")
+
+	return img
+}
+
+// Render writes the chart as a PNG image
+func (c *CalendarHeatmap) Render(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
@@ -0,0 +1,66 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+const (
+	lineWidth   = 3
+	pointRadius = 3
+)
+
+// LineChart draws a line through the values of each series
+type LineChart struct {
+	Width  int
+	Height int
+	Theme  Theme
+	Labels []string
+	Series []Series
+	// Threshold draws a dashed horizontal line at its value when non-zero
+	Threshold      float64
+	ThresholdColor color.Color
+}
+
+func (c *LineChart) maxValue() (m float64) {
+	for _, s := range c.Series {
+		for i := range c.Labels {
+			m = max(m, seriesValue(s, i))
+		}
+	}
+
+	return max(m, c.Threshold)
+}
+
+// Image draws the chart
+func (c *LineChart) Image() image.Image {
+	img := newCanvas(c.Width, c.Height, c.Theme.Background)
+	a := newAxes(c.Width, c.Height, c.Theme, c.Labels, c.Series, c.maxValue())
+
+	a.drawGrid(img)
+	a.drawLegend(img, c.Series)
+	a.drawThreshold(img, c.Threshold, c.ThresholdColor)
+
+	for _, s := range c.Series {
+		for i := range c.Labels {
+			x, y := a.x(i), a.y(seriesValue(s, i))
+
+			if i > 0 {
+				drawLine(img, a.x(i-1), a.y(seriesValue(s, i-1)), x, y, lineWidth, 0, s.Color)
+			}
+
+			fillCircle(img, x, y, pointRadius, s.Color)
+		}
+	}
+
+	a.drawLabels(img)
+
+	return img
+}
+
+// Render writes the chart as a PNG image
+func (c *LineChart) Render(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
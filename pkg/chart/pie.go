@@ -0,0 +1,172 @@
+package chart
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+
+	"golang.org/x/image/font"
+)
+
+const (
+	// slices smaller than this fraction of the total are not labeled
+	pieMinLabelFraction = 0.04
+	// samples per pixel along each axis, used to smooth the edges of slices
+	pieSamples = 3
+)
+
+var (
+	pieBorderColor     = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	pieLegendColor     = color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	pieLabelBackground = color.RGBA{0xcc, 0xcc, 0xcc, 0xff}
+	pieLabelColor      = color.RGBA{0x33, 0x33, 0x33, 0xff}
+)
+
+// PieChart draws a slice for each label with a legend above it,
+// a nil Theme.Background leaves the image transparent
+type PieChart struct {
+	Width  int
+	Height int
+	Theme  Theme
+	Labels []string
+	Values []float64
+	// Colors of the slices, defaults to DefaultPalette
+	Colors []color.Color
+}
+
+func (c *PieChart) color(i int) color.Color {
+	colors := c.Colors
+	if len(colors) == 0 {
+		colors = DefaultPalette
+	}
+
+	return colors[i%len(colors)]
+}
+
+// Image draws the chart
+func (c *PieChart) Image() image.Image {
+	img := newCanvas(c.Width, c.Height, c.Theme.Background)
+	legendFace := newFace(14)
+	labelFace := newFace(18)
+
+	series := make([]Series, len(c.Labels))
+	for i, label := range c.Labels {
+		series[i] = Series{Name: label, Color: c.color(i)}
+	}
+
+	top := paddingY
+	if len(series) > 0 {
+		drawLegend(img, legendFace, pieLegendColor, top, series)
+		top += legendHeight(legendFace, series, c.Width) + legendGap
+	}
+
+	total := 0.0
+	for _, v := range c.Values {
+		total += max(v, 0)
+	}
+
+	if total == 0 {
+		return img
+	}
+
+	area := image.Rect(paddingY, top, c.Width-paddingY, c.Height-paddingY)
+	radius := float64(min(area.Dx(), area.Dy())) / 2
+	cx := float64(area.Min.X) + float64(area.Dx())/2
+	cy := float64(area.Min.Y) + float64(area.Dy())/2
+
+	// angles at which each slice ends, clockwise from the top
+	ends := make([]float64, len(c.Values))
+	angle := 0.0
+	for i, v := range c.Values {
+		angle += max(v, 0) / total * 2 * math.Pi
+		ends[i] = angle
+	}
+
+	c.drawSlices(img, cx, cy, radius, ends)
+
+	start := 0.0
+	for i, end := range ends {
+		if len(c.Values) > 1 {
+			drawLine(img, cx, cy, cx+radius*math.Sin(start), cy-radius*math.Cos(start), 2, 0, pieBorderColor)
+		}
+
+		if (end-start)/(2*math.Pi) >= pieMinLabelFraction {
+			middle := (start + end) / 2
+			c.drawValueLabel(img, labelFace, cx+radius*0.65*math.Sin(middle), cy-radius*0.65*math.Cos(middle), c.Values[i])
+		}
+
+		start = end
+	}
+
+	return img
+}
+
+func (c *PieChart) drawSlices(img *image.RGBA, cx, cy, radius float64, ends []float64) {
+	step := 1 / float64(pieSamples)
+
+	for y := int(cy - radius); y <= int(cy+radius); y++ {
+		for x := int(cx - radius); x <= int(cx+radius); x++ {
+			var r, g, b, a uint32
+
+			for sy := 0; sy < pieSamples; sy++ {
+				for sx := 0; sx < pieSamples; sx++ {
+					dx := float64(x) + (float64(sx)+0.5)*step - cx
+					dy := float64(y) + (float64(sy)+0.5)*step - cy
+
+					if math.Hypot(dx, dy) > radius {
+						continue
+					}
+
+					angle := math.Atan2(dx, -dy)
+					if angle < 0 {
+						angle += 2 * math.Pi
+					}
+
+					i := 0
+					for i < len(ends)-1 && angle > ends[i] {
+						i++
+					}
+
+					sr, sg, sb, sa := c.color(i).RGBA()
+					r, g, b, a = r+sr, g+sg, b+sb, a+sa
+				}
+			}
+
+			if a == 0 {
+				continue
+			}
+
+			samples := uint32(pieSamples * pieSamples)
+			fillRect(img, image.Rect(x, y, x+1, y+1), color.RGBA64{
+				R: uint16(r / samples),
+				G: uint16(g / samples),
+				B: uint16(b / samples),
+				A: uint16(a / samples),
+			})
+		}
+	}
+}
+
+func (c *PieChart) drawValueLabel(img *image.RGBA, face font.Face, x, y, value float64) {
+	text := fmt.Sprintf("%.0f", value)
+	width := textWidth(face, text)
+	height := face.Metrics().Height.Ceil()
+
+	box := image.Rect(
+		int(x)-width/2-4,
+		int(y)-height/2-2,
+		int(x)+width/2+4,
+		int(y)+height/2+2,
+	)
+
+	fillRect(img, box, pieLabelBackground)
+	drawText(img, face, pieLabelColor, box.Min.X+4, box.Min.Y+2, text)
+}
+
+// Render writes the chart as a PNG image
+func (c *PieChart) Render(w io.Writer) error {
+	return png.Encode(w, c.Image())
+}
@@ -89,3 +89,23 @@ func TestUnmarshalOptions(t *testing.T) {
 
 	assert.Equal(t, expected, actual)
 }
+
+func TestUnmarshalOptionsRequired(t *testing.T) {
+	type testType struct {
+		Field1 string `discordopt:"field1,required"`
+		Field2 int    `discordopt:"field2,required"`
+	}
+
+	options := []*discordgo.ApplicationCommandInteractionDataOption{
+		{
+			Value: "value1",
+			Name:  "field1",
+			Type:  discordgo.ApplicationCommandOptionString,
+		},
+	}
+
+	var actual testType
+	err := discordutil.UnmarshalOptions(options, &actual)
+	assert.ErrorContains(t, err, "required option not found: field2")
+	assert.Equal(t, "value1", actual.Field1)
+}